@@ -0,0 +1,7 @@
+import {
+  to = rhsm_cloud_access_account.example
+  identity = {
+    provider_short_name = "AWS"
+    account_id          = "012345678912"
+  }
+}
@@ -0,0 +1,4 @@
+import {
+  to = rhsm_cloud_access_account.example
+  id = "AWS:012345678912"
+}
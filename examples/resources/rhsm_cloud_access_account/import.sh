@@ -0,0 +1,5 @@
+# Import an account by its cloud provider short name and account ID
+terraform import rhsm_cloud_access_account.example AWS:012345678912
+
+# Import an account by its account ID alone if it is only enabled in one cloud provider
+terraform import rhsm_cloud_access_account.example 012345678912
@@ -0,0 +1,14 @@
+resource "rhsm_cloud_access_account" "test_account" {
+  account_id          = "012345678912"
+  provider_short_name = "AWS"
+  nickname            = "Test AWS Account"
+}
+
+// The identity document and signature come from the metadata service of an
+// EC2 instance running in the account.
+resource "rhsm_cloud_access_account_verification" "test_account" {
+  account_id          = rhsm_cloud_access_account.test_account.account_id
+  provider_short_name = rhsm_cloud_access_account.test_account.provider_short_name
+  identity            = base64encode(var.instance_identity_document)
+  signature           = var.instance_identity_signature
+}
@@ -0,0 +1,2 @@
+# Import every account enabled for a cloud provider by its short name
+terraform import rhsm_cloud_access_accounts.aws AWS
@@ -0,0 +1,13 @@
+resource "rhsm_cloud_access_accounts" "aws" {
+  provider_short_name = "AWS"
+
+  accounts = {
+    "012345678912" = {
+      nickname    = "Test AWS Account"
+      gold_images = ["RHEL"]
+    }
+    "012345678913" = {
+      nickname = "Another Test AWS Account"
+    }
+  }
+}
@@ -0,0 +1,2 @@
+# Import a gold image request by its cloud provider short name, account ID and image name
+terraform import rhsm_cloud_access_gold_image.rhel_ha AWS:012345678912:rhel-ha
@@ -0,0 +1,14 @@
+// gold_images is left unset so the account does not manage the images
+// requested below.
+resource "rhsm_cloud_access_account" "shared" {
+  account_id          = "012345678912"
+  provider_short_name = "AWS"
+  nickname            = "Shared AWS Account"
+}
+
+// Each team can request the gold images it needs for the shared account.
+resource "rhsm_cloud_access_gold_image" "rhel_ha" {
+  account_id          = rhsm_cloud_access_account.shared.account_id
+  provider_short_name = rhsm_cloud_access_account.shared.provider_short_name
+  image               = "rhel-ha"
+}
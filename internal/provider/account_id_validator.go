@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// accountIDFormat is the format of the account IDs of a cloud provider.
+type accountIDFormat struct {
+	pattern     *regexp.Regexp
+	description string
+}
+
+// accountIDFormats are the account ID formats of the cloud providers that
+// have a well known format. Account IDs for other providers are not checked.
+var accountIDFormats = map[string]accountIDFormat{
+	"AWS": {
+		pattern:     regexp.MustCompile(`^[0-9]{12}$`),
+		description: "a 12 digit AWS account ID",
+	},
+	"MSAZ": {
+		pattern:     regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+		description: "an Azure subscription ID in GUID format, such as 123e4567-e89b-12d3-a456-426614174000",
+	},
+	"GCE": {
+		pattern:     regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`),
+		description: "the email address of a Google Group",
+	},
+}
+
+// checkAccountID returns an error if accountID does not match the account ID
+// format of the cloud provider.
+func checkAccountID(shortName string, accountID string) error {
+	format, ok := accountIDFormats[shortName]
+	if !ok || format.pattern.MatchString(accountID) {
+		return nil
+	}
+
+	return fmt.Errorf("%q is not %s", accountID, format.description)
+}
+
+var _ resource.ConfigValidator = accountIDValidator{}
+
+// accountIDValidator checks the format of the account_id attribute against
+// the cloud provider in the provider_short_name attribute.
+type accountIDValidator struct{}
+
+func (v accountIDValidator) Description(ctx context.Context) string {
+	return v.MarkdownDescription(ctx)
+}
+
+func (v accountIDValidator) MarkdownDescription(ctx context.Context) string {
+	return "account_id must match the account ID format of the cloud provider in provider_short_name"
+}
+
+func (v accountIDValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var shortName, accountID types.String
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("provider_short_name"), &shortName)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("account_id"), &accountID)...)
+
+	if resp.Diagnostics.HasError() || !isKnown(shortName) || !isKnown(accountID) {
+		return
+	}
+
+	if err := checkAccountID(shortName.ValueString(), accountID.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("account_id"),
+			"Invalid account_id",
+			fmt.Sprintf("The account_id is not valid for %s: %s.", shortName.ValueString(), err),
+		)
+	}
+}
+
+var _ resource.ConfigValidator = accountsValidator{}
+
+// accountsValidator checks the format of the keys of the accounts attribute
+// against the cloud provider in the provider_short_name attribute.
+type accountsValidator struct{}
+
+func (v accountsValidator) Description(ctx context.Context) string {
+	return v.MarkdownDescription(ctx)
+}
+
+func (v accountsValidator) MarkdownDescription(ctx context.Context) string {
+	return "the keys of accounts must match the account ID format of the cloud provider in provider_short_name"
+}
+
+func (v accountsValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var shortName types.String
+	var accounts types.Map
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("provider_short_name"), &shortName)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("accounts"), &accounts)...)
+
+	if resp.Diagnostics.HasError() || !isKnown(shortName) || accounts.IsNull() || accounts.IsUnknown() {
+		return
+	}
+
+	for accountID := range accounts.Elements() {
+		if err := checkAccountID(shortName.ValueString(), accountID); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("accounts").AtMapKey(accountID),
+				"Invalid account ID",
+				fmt.Sprintf("The account ID is not valid for %s: %s.", shortName.ValueString(), err),
+			)
+		}
+	}
+}
+
+// isKnown reports whether a value is set in the configuration and known.
+func isKnown(v types.String) bool {
+	return !v.IsNull() && !v.IsUnknown()
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+)
+
+func TestCheckAccountID(t *testing.T) {
+	cases := []struct {
+		shortName string
+		accountID string
+		valid     bool
+	}{
+		{"AWS", "012345678912", true},
+		{"AWS", "01234567891", false},
+		{"AWS", "01234567891a", false},
+		{"MSAZ", "123e4567-e89b-12d3-a456-426614174000", true},
+		{"MSAZ", "123E4567-E89B-12D3-A456-426614174000", true},
+		{"MSAZ", "123e4567e89b12d3a456426614174000", false},
+		{"GCE", "test.group@example.com", true},
+		{"GCE", "test.group", false},
+		{"IBM", "anything", true},
+	}
+
+	for _, tc := range cases {
+		err := checkAccountID(tc.shortName, tc.accountID)
+		if tc.valid && err != nil {
+			t.Errorf("expected %s account ID %q to be valid, got %s", tc.shortName, tc.accountID, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("expected %s account ID %q to be invalid", tc.shortName, tc.accountID)
+		}
+	}
+}
+
+func TestAccountIDValidator(t *testing.T) {
+	ctx := context.Background()
+
+	plan := testCloudAccessAccountPlan(t, &CloudAccessAccountResource{}, nil, goldImagesRemovalWarn)
+	if diags := plan.SetAttribute(ctx, path.Root("account_id"), "01234567891"); diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	resp := &fwresource.ValidateConfigResponse{}
+	accountIDValidator{}.ValidateResource(ctx, fwresource.ValidateConfigRequest{
+		Config: tfsdk.Config{Schema: plan.Schema, Raw: plan.Raw},
+	}, resp)
+
+	if resp.Diagnostics.ErrorsCount() != 1 {
+		t.Fatalf("expected 1 error, got %v", resp.Diagnostics)
+	}
+	if got, ok := resp.Diagnostics.Errors()[0].(diag.DiagnosticWithPath); !ok || !got.Path().Equal(path.Root("account_id")) {
+		t.Errorf("expected the error to be on account_id, got %v", got)
+	}
+}
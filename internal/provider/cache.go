@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/umich-vci/gorhsm"
+	"golang.org/x/sync/singleflight"
+)
+
+// cloudAccessCacheTTL is how long a ListEnabledCloudAccessProviders response
+// is reused before the API is called again.
+const cloudAccessCacheTTL = 30 * time.Second
+
+// cloudAccessListTimeout bounds a shared ListEnabledCloudAccessProviders
+// call, which is not cancelled by its callers. It is a variable so that
+// tests can shorten it.
+var cloudAccessListTimeout = defaultReadTimeout
+
+// cloudAccessSnapshot is a single ListEnabledCloudAccessProviders response.
+type cloudAccessSnapshot struct {
+	caps    *gorhsm.ListEnabledCloudAccessProviders200Response
+	resp    *http.Response
+	fetched time.Time
+}
+
+// cloudAccessCache shares ListEnabledCloudAccessProviders responses between
+// every resource and data source using a provider instance. Concurrent
+// requests for the same snapshot result in a single API call.
+type cloudAccessCache struct {
+	list func(ctx context.Context) (*gorhsm.ListEnabledCloudAccessProviders200Response, *http.Response, error)
+
+	group singleflight.Group
+
+	mu         sync.Mutex
+	snapshot   *cloudAccessSnapshot
+	generation uint64
+}
+
+func newCloudAccessCache(client *gorhsm.APIClient) *cloudAccessCache {
+	return &cloudAccessCache{
+		list: func(ctx context.Context) (*gorhsm.ListEnabledCloudAccessProviders200Response, *http.Response, error) {
+			return client.CloudaccessAPI.ListEnabledCloudAccessProviders(ctx).Execute()
+		},
+	}
+}
+
+// get returns the cached providers, fetching them if the cache is empty or
+// older than cloudAccessCacheTTL. The returned response is shared between
+// callers and must be treated as read only; its body must not be read.
+func (c *cloudAccessCache) get(ctx context.Context) (*gorhsm.ListEnabledCloudAccessProviders200Response, *http.Response, error) {
+	c.mu.Lock()
+	snapshot := c.snapshot
+	generation := c.generation
+	c.mu.Unlock()
+
+	if snapshot != nil && time.Since(snapshot.fetched) < cloudAccessCacheTTL {
+		return snapshot.caps, snapshot.resp, nil
+	}
+
+	// Callers joining after an invalidation use a new key so they never
+	// receive a response fetched before the change that invalidated it.
+	ch := c.group.DoChan(strconv.FormatUint(generation, 10), func() (interface{}, error) {
+		// the fetch is shared, so one caller giving up must not cancel
+		// it for the others, but it still needs a deadline of its own so
+		// a hung request does not block every later caller
+		listCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cloudAccessListTimeout)
+		defer cancel()
+
+		caps, resp, err := c.list(listCtx)
+		snapshot := &cloudAccessSnapshot{caps: caps, resp: resp, fetched: time.Now()}
+		if err != nil {
+			return snapshot, err
+		}
+
+		c.mu.Lock()
+		if c.generation == generation {
+			c.snapshot = snapshot
+		}
+		c.mu.Unlock()
+
+		return snapshot, nil
+	})
+
+	select {
+	case result := <-ch:
+		snapshot, ok := result.Val.(*cloudAccessSnapshot)
+		if !ok {
+			return nil, nil, result.Err
+		}
+		return snapshot.caps, snapshot.resp, result.Err
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+// invalidate discards the cached providers. It must be called after any
+// request that changes cloud access accounts or gold images.
+func (c *cloudAccessCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.snapshot = nil
+	c.generation++
+}
+
+// shortNames returns the short names of the cloud providers enabled for the
+// organization. If they cannot be listed, for example when no credentials
+// are available at plan time, cloudAccessAccountProviders is returned so
+// that validation still works offline.
+func (c *cloudAccessCache) shortNames(ctx context.Context) []string {
+	caps, _, err := c.get(ctx)
+	if err != nil {
+		tflog.Debug(ctx, "Falling back to the default cloud access providers", map[string]interface{}{"error": err.Error()})
+		return cloudAccessAccountProviders
+	}
+
+	names := []string{}
+	for _, x := range caps.GetBody() {
+		names = append(names, x.GetShortName())
+	}
+
+	return names
+}
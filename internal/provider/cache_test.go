@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/umich-vci/gorhsm"
+)
+
+func testCloudAccessCache() (*cloudAccessCache, *int32) {
+	var calls int32
+	return &cloudAccessCache{
+		list: func(ctx context.Context) (*gorhsm.ListEnabledCloudAccessProviders200Response, *http.Response, error) {
+			atomic.AddInt32(&calls, 1)
+			// hold the call open long enough for concurrent callers to join it
+			time.Sleep(10 * time.Millisecond)
+			return &gorhsm.ListEnabledCloudAccessProviders200Response{}, nil, nil
+		},
+	}, &calls
+}
+
+func TestCloudAccessCacheDeduplicatesRequests(t *testing.T) {
+	cache, calls := testCloudAccessCache()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := cache.get(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, _, err := cache.get(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if *calls != 1 {
+		t.Fatalf("expected 1 API call, got %d", *calls)
+	}
+}
+
+func TestCloudAccessCacheInvalidate(t *testing.T) {
+	cache, calls := testCloudAccessCache()
+
+	if _, _, err := cache.get(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	cache.invalidate()
+
+	if _, _, err := cache.get(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if *calls != 2 {
+		t.Fatalf("expected 2 API calls, got %d", *calls)
+	}
+}
+
+func TestCloudAccessCacheExpires(t *testing.T) {
+	cache, calls := testCloudAccessCache()
+
+	if _, _, err := cache.get(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	cache.snapshot.fetched = time.Now().Add(-cloudAccessCacheTTL)
+
+	if _, _, err := cache.get(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if *calls != 2 {
+		t.Fatalf("expected 2 API calls, got %d", *calls)
+	}
+}
+
+func TestCloudAccessCacheListTimeout(t *testing.T) {
+	cloudAccessListTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { cloudAccessListTimeout = defaultReadTimeout })
+
+	cache := &cloudAccessCache{
+		list: func(ctx context.Context) (*gorhsm.ListEnabledCloudAccessProviders200Response, *http.Response, error) {
+			// a request that hangs until its context ends
+			<-ctx.Done()
+			return nil, nil, ctx.Err()
+		},
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := cache.get(context.Background())
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected the shared request to time out, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the shared request to time out")
+	}
+}
+
+func TestCloudAccessCacheShortNames(t *testing.T) {
+	cache := &cloudAccessCache{
+		list: func(ctx context.Context) (*gorhsm.ListEnabledCloudAccessProviders200Response, *http.Response, error) {
+			return &gorhsm.ListEnabledCloudAccessProviders200Response{
+				Body: []gorhsm.EnabledCloudAccessProvider{
+					{ShortName: gorhsm.PtrString("AWS")},
+					{ShortName: gorhsm.PtrString("IBM")},
+				},
+			}, nil, nil
+		},
+	}
+
+	got := cache.shortNames(context.Background())
+	if !slices.Equal(got, []string{"AWS", "IBM"}) {
+		t.Fatalf("expected [AWS IBM], got %v", got)
+	}
+}
+
+func TestCloudAccessCacheShortNamesFallback(t *testing.T) {
+	cache := &cloudAccessCache{
+		list: func(ctx context.Context) (*gorhsm.ListEnabledCloudAccessProviders200Response, *http.Response, error) {
+			return nil, nil, errors.New("no credentials")
+		},
+	}
+
+	got := cache.shortNames(context.Background())
+	if !slices.Equal(got, cloudAccessAccountProviders) {
+		t.Fatalf("expected %v, got %v", cloudAccessAccountProviders, got)
+	}
+}
@@ -224,12 +224,9 @@ func (d *CloudAccessDataSource) Read(ctx context.Context, req datasource.ReadReq
 		return
 	}
 
-	client := d.client.Client
-	auth := d.client.Auth
-
-	ecap, _, err := client.CloudaccessAPI.ListEnabledCloudAccessProviders(auth).Execute()
+	ecap, lecap, err := d.client.CloudAccess.get(ctx)
 	if err != nil {
-		resp.Diagnostics.AddError("failed to list enabled cloud access providers", err.Error())
+		addAPIError(&resp.Diagnostics, "Failed to list enabled cloud access providers", lecap, err)
 		return
 	}
 
@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/umich-vci/gorhsm"
+)
+
+// errAccessToken is wrapped by errors returned when an access token could
+// not be generated for a request.
+var errAccessToken = errors.New("failed to generate access token")
+
+// requestIDHeaders are the response headers that may hold the ID RHSM
+// assigned to a request, in order of preference.
+var requestIDHeaders = []string{
+	"X-Rh-Insights-Request-Id",
+	"X-Request-Id",
+	"X-Correlation-Id",
+}
+
+// alreadyRegisteredPattern matches RHSM error messages for a cloud account
+// that is enabled for Cloud Access in another organization.
+var alreadyRegisteredPattern = regexp.MustCompile(`(?i)already.*(another|different|other)`)
+
+// maxErrorBodyLength is the most of an unrecognized error body that is
+// included in a diagnostic.
+const maxErrorBodyLength = 1024
+
+// addAPIError adds an error to diags for err returned by an RHSM API request
+// that received resp. summary describes what failed and is extended with the
+// likely cause when the status code has a well known meaning.
+func addAPIError(diags *diag.Diagnostics, summary string, resp *http.Response, err error) {
+	cause, detail := describeAPIError(resp, err)
+	if cause != "" {
+		summary = fmt.Sprintf("%s: %s", summary, cause)
+	}
+
+	diags.AddError(summary, detail)
+}
+
+// describeAPIError returns a short cause and a detailed explanation of err
+// returned by an RHSM API request that received resp.
+func describeAPIError(resp *http.Response, err error) (cause string, detail string) {
+	if errors.Is(err, errAccessToken) {
+		return "could not generate an access token",
+			fmt.Sprintf("The provider could not generate an RHSM access token. "+
+				"Check that the refresh_token or the client_id and client_secret are valid and that token_url is reachable.\n\n%s", err)
+	}
+
+	if resp == nil {
+		return "", err.Error()
+	}
+
+	message := apiErrorMessage(err)
+
+	var hint string
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		cause = "access token rejected"
+		hint = "RHSM rejected the access token, which usually means it has expired or the credentials have been revoked. " +
+			"The request was already retried with a new token, so check that the refresh_token or service account is still valid."
+	case resp.StatusCode == http.StatusForbidden:
+		cause = "insufficient Cloud Access entitlement or permissions"
+		hint = "RHSM refused the request. Check that the organization has subscriptions that are eligible for Red Hat Cloud Access " +
+			"and that the user or service account the provider authenticates as is allowed to manage Cloud Access."
+	case (resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusConflict) && alreadyRegisteredPattern.MatchString(message):
+		cause = "account already registered to another organization"
+		hint = "A cloud account can only be enabled for Red Hat Cloud Access in one Red Hat organization. " +
+			"Remove it from the other organization before adding it to this one."
+	case resp.StatusCode == http.StatusNotFound:
+		cause = "not found"
+		hint = "RHSM could not find the cloud provider or account. Check provider_short_name and account_id."
+	case resp.StatusCode == http.StatusTooManyRequests:
+		cause = "rate limited"
+		hint = "RHSM kept throttling requests after they were retried. " +
+			"Lower requests_per_second or max_concurrent_requests in the provider configuration, or raise max_retries."
+	case resp.StatusCode >= http.StatusInternalServerError:
+		cause = "RHSM API error"
+		hint = "The RHSM API could not process the request. This is usually temporary, so try again later."
+	case resp.StatusCode == http.StatusBadRequest:
+		cause = "request rejected"
+	}
+
+	var b strings.Builder
+	if hint != "" {
+		b.WriteString(hint)
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "RHSM API response: %s", resp.Status)
+	if message != "" {
+		fmt.Fprintf(&b, ": %s", message)
+	}
+	if requestID := apiRequestID(resp); requestID != "" {
+		fmt.Fprintf(&b, "\nRequest ID: %s", requestID)
+	}
+
+	return cause, b.String()
+}
+
+// apiErrorPayload is the error body returned by every RHSM API endpoint.
+type apiErrorPayload struct {
+	Error *gorhsm.ErrorDetails `json:"error,omitempty"`
+}
+
+// apiErrorMessage returns the message from the RHSM error payload in err, or
+// the raw body if it is not a recognized payload.
+func apiErrorMessage(err error) string {
+	var apiErr *gorhsm.GenericOpenAPIError
+	if !errors.As(err, &apiErr) {
+		return err.Error()
+	}
+
+	body := apiErr.Body()
+
+	var payload apiErrorPayload
+	if json.Unmarshal(body, &payload) == nil && payload.Error != nil && payload.Error.GetMessage() != "" {
+		return payload.Error.GetMessage()
+	}
+
+	message := strings.TrimSpace(string(body))
+	if len(message) > maxErrorBodyLength {
+		message = message[:maxErrorBodyLength] + "..."
+	}
+
+	return message
+}
+
+// apiRequestID returns the ID RHSM assigned to the request that received
+// resp, if there is one.
+func apiRequestID(resp *http.Response) string {
+	for _, header := range requestIDHeaders {
+		if id := resp.Header.Get(header); id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/umich-vci/gorhsm"
+)
+
+func TestAddAPIError(t *testing.T) {
+	cases := map[string]struct {
+		status      int
+		contentType string
+		body        string
+		requestID   string
+		summary     string
+		detail      []string
+	}{
+		"token expired": {
+			status:      http.StatusUnauthorized,
+			contentType: "application/json",
+			body:        `{"error":{"code":401,"message":"token is expired"}}`,
+			summary:     "Failed to remove Cloud Access Account: access token rejected",
+			detail:      []string{"401 Unauthorized: token is expired"},
+		},
+		"already registered": {
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+			body:        `{"error":{"code":400,"message":"account 012345678912 is already registered to another organization"}}`,
+			summary:     "Failed to remove Cloud Access Account: account already registered to another organization",
+			detail:      []string{"400 Bad Request: account 012345678912 is already registered"},
+		},
+		"forbidden": {
+			status:      http.StatusForbidden,
+			contentType: "application/json",
+			body:        `{"error":{"code":403,"message":"forbidden"}}`,
+			requestID:   "abc123",
+			summary:     "Failed to remove Cloud Access Account: insufficient Cloud Access entitlement or permissions",
+			detail:      []string{"403 Forbidden: forbidden", "Request ID: abc123"},
+		},
+		"unrecognized body": {
+			status:      http.StatusBadGateway,
+			contentType: "text/html",
+			body:        "<html>upstream error</html>",
+			summary:     "Failed to remove Cloud Access Account: RHSM API error",
+			detail:      []string{"502 Bad Gateway: <html>upstream error</html>"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := testAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.requestID != "" {
+					w.Header().Set("X-Rh-Insights-Request-Id", tc.requestID)
+				}
+				w.Header().Set("Content-Type", tc.contentType)
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+
+			resp, err := client.Client.CloudaccessAPI.RemoveProviderAccount(context.Background(), "AWS").
+				Account(gorhsm.RemoveProviderAccountRequest{Id: "012345678912"}).Execute()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			var diags diag.Diagnostics
+			addAPIError(&diags, "Failed to remove Cloud Access Account", resp, err)
+
+			if diags.ErrorsCount() != 1 {
+				t.Fatalf("expected 1 error, got %v", diags)
+			}
+			if got := diags[0].Summary(); got != tc.summary {
+				t.Errorf("expected summary %q, got %q", tc.summary, got)
+			}
+			for _, want := range tc.detail {
+				if got := diags[0].Detail(); !strings.Contains(got, want) {
+					t.Errorf("expected detail to contain %q, got %q", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAddAPIErrorWithoutResponse(t *testing.T) {
+	var diags diag.Diagnostics
+	addAPIError(&diags, "Failed to remove Cloud Access Account", nil, errors.New("connection refused"))
+
+	if got := diags[0].Summary(); got != "Failed to remove Cloud Access Account" {
+		t.Errorf("unexpected summary %q", got)
+	}
+	if got := diags[0].Detail(); got != "connection refused" {
+		t.Errorf("unexpected detail %q", got)
+	}
+
+	diags = nil
+	addAPIError(&diags, "Failed to remove Cloud Access Account", nil, fmt.Errorf("%w: invalid_grant", errAccessToken))
+
+	if got := diags[0].Summary(); got != "Failed to remove Cloud Access Account: could not generate an access token" {
+		t.Errorf("unexpected summary %q", got)
+	}
+}
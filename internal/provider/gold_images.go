@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/umich-vci/gorhsm"
+)
+
+const (
+	// goldImageStatusGranted and goldImageStatusFailed are the final statuses
+	// of a gold image request. Requests are "Requested" until then.
+	goldImageStatusGranted = "Granted"
+	goldImageStatusFailed  = "Failed"
+)
+
+// waitForGoldImages polls the enabled cloud access providers until every one
+// of images has reached a final status for an account. It returns an error
+// naming each image that was not granted.
+func waitForGoldImages(ctx context.Context, client *apiClient, shortName string, accountID string, images []string) diag.Diagnostics {
+	return waitFor(ctx, "wait for gold image requests", func() (bool, diag.Diagnostics) {
+		var d diag.Diagnostics
+
+		// always ask the API for the current status
+		client.CloudAccess.invalidate()
+
+		caps, lecap, err := client.CloudAccess.get(ctx)
+		if err != nil {
+			if addTimeoutError(ctx, &d, "wait for gold image requests") {
+				return false, d
+			}
+			addAPIError(&d, "Failed to list enabled cloud access providers", lecap, err)
+			return false, d
+		}
+
+		account := findProviderAccount(caps, shortName, accountID)
+		if account == nil {
+			d.AddError("Failed to wait for gold image requests", "No matching account was found")
+			return false, d
+		}
+
+		pending, failed := goldImageRequestStatus(account.GetGoldImageStatus(), images)
+		if len(failed) > 0 {
+			details := make([]string, len(failed))
+			for i, x := range failed {
+				details[i] = fmt.Sprintf("%s (%s): %s", x.GetName(), x.GetDescription(), x.GetStatus())
+			}
+			d.AddError("Gold image request was not granted",
+				fmt.Sprintf("The following gold image requests for account %s were not granted:\n\n%s",
+					accountID, strings.Join(details, "\n")))
+			return false, d
+		}
+
+		if len(pending) > 0 {
+			tflog.Debug(ctx, "Gold image requests are pending", map[string]interface{}{"pending": strings.Join(pending, ", ")})
+			return false, d
+		}
+
+		return true, d
+	})
+}
+
+// goldImageRequestStatus returns the names of the images that are still
+// pending and the statuses of the images whose requests failed.
+func goldImageRequestStatus(statuses []gorhsm.GoldImageStatus, images []string) (pending []string, failed []gorhsm.GoldImageStatus) {
+	for _, image := range images {
+		status := findGoldImageStatus(statuses, image)
+
+		switch {
+		case status == nil:
+			// the request has not shown up yet
+			pending = append(pending, image)
+		case strings.EqualFold(status.GetStatus(), goldImageStatusGranted):
+		case strings.EqualFold(status.GetStatus(), goldImageStatusFailed):
+			failed = append(failed, *status)
+		default:
+			pending = append(pending, image)
+		}
+	}
+
+	return pending, failed
+}
+
+// findGoldImageStatus returns the status of the request for image, or nil if
+// image has not been requested.
+func findGoldImageStatus(statuses []gorhsm.GoldImageStatus, image string) *gorhsm.GoldImageStatus {
+	for i := range statuses {
+		if statuses[i].GetName() == image {
+			return &statuses[i]
+		}
+	}
+
+	return nil
+}
+
+// findProviderAccount returns the account enabled for a cloud provider, or nil
+// if there is no such account.
+func findProviderAccount(caps *gorhsm.ListEnabledCloudAccessProviders200Response, shortName string, accountID string) *gorhsm.EnabledProviderAccount {
+	if caps == nil {
+		return nil
+	}
+
+	for _, x := range caps.GetBody() {
+		if x.GetShortName() != shortName {
+			continue
+		}
+
+		for _, y := range x.GetAccounts() {
+			if y.Id == accountID {
+				return &y
+			}
+		}
+	}
+
+	return nil
+}
+
+// removedGoldImages returns the images in prior that are missing from
+// planned. Terraform only accepts a planned value that differs from the
+// configuration if it is the prior value, so keepPrior is only true when no
+// image was added and the prior images can be planned instead.
+func removedGoldImages(planned []string, prior []string) (removed []string, keepPrior bool) {
+	for _, image := range prior {
+		if !slices.Contains(planned, image) {
+			removed = append(removed, image)
+		}
+	}
+
+	keepPrior = len(removed) > 0
+	for _, image := range planned {
+		if !slices.Contains(prior, image) {
+			keepPrior = false
+		}
+	}
+
+	return removed, keepPrior
+}
@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/umich-vci/gorhsm"
+)
+
+func TestGoldImageRequestStatus(t *testing.T) {
+	statuses := []gorhsm.GoldImageStatus{
+		{Name: gorhsm.PtrString("RHEL"), Description: gorhsm.PtrString("Red Hat Enterprise Linux"), Status: gorhsm.PtrString("Granted")},
+		{Name: gorhsm.PtrString("RHEL-HA"), Description: gorhsm.PtrString("Red Hat Enterprise Linux High Availability"), Status: gorhsm.PtrString("Requested")},
+		{Name: gorhsm.PtrString("RHEL-SAP"), Description: gorhsm.PtrString("Red Hat Enterprise Linux for SAP"), Status: gorhsm.PtrString("Failed")},
+	}
+
+	pending, failed := goldImageRequestStatus(statuses, []string{"RHEL", "RHEL-HA", "RHEL-SAP", "RHEL-NEW"})
+
+	if !slices.Equal(pending, []string{"RHEL-HA", "RHEL-NEW"}) {
+		t.Errorf("expected RHEL-HA and RHEL-NEW to be pending, got %v", pending)
+	}
+
+	if len(failed) != 1 || failed[0].GetName() != "RHEL-SAP" {
+		t.Errorf("expected RHEL-SAP to have failed, got %v", failed)
+	}
+}
+
+func TestGoldImageRequestStatusGranted(t *testing.T) {
+	statuses := []gorhsm.GoldImageStatus{
+		{Name: gorhsm.PtrString("RHEL"), Status: gorhsm.PtrString("Granted")},
+	}
+
+	pending, failed := goldImageRequestStatus(statuses, []string{"RHEL"})
+
+	if len(pending) != 0 || len(failed) != 0 {
+		t.Fatalf("expected all requests to be granted, got pending %v and failed %v", pending, failed)
+	}
+}
@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/time/rate"
+)
+
+// requestLimiter caps the rate and the number of concurrent requests sent to
+// the RHSM API. A single requestLimiter is shared by every resource and data
+// source configured by the provider.
+type requestLimiter struct {
+	// rate is nil when the request rate is not limited.
+	rate *rate.Limiter
+
+	// slots is nil when concurrent requests are not limited.
+	slots chan struct{}
+}
+
+// newRequestLimiter returns a requestLimiter allowing requestsPerSecond and
+// maxConcurrent requests. A value of zero disables that limit.
+func newRequestLimiter(requestsPerSecond float64, maxConcurrent int) *requestLimiter {
+	l := new(requestLimiter)
+
+	if requestsPerSecond > 0 {
+		l.rate = rate.NewLimiter(rate.Limit(requestsPerSecond), int(math.Max(1, math.Ceil(requestsPerSecond))))
+	}
+
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+
+	return l
+}
+
+// acquire blocks until a request may be sent. The returned function must be
+// called once the request has completed.
+func (l *requestLimiter) acquire(ctx context.Context) (func(), error) {
+	release := func() {}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			tflog.Debug(ctx, "Waiting for another RHSM API request to finish", map[string]interface{}{
+				"max_concurrent_requests": cap(l.slots),
+			})
+			select {
+			case l.slots <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		release = func() { <-l.slots }
+	}
+
+	if l.rate != nil {
+		reservation := l.rate.Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			tflog.Debug(ctx, "Waiting for the RHSM API rate limit", map[string]interface{}{
+				"delay":               delay.String(),
+				"requests_per_second": float64(l.rate.Limit()),
+			})
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				reservation.Cancel()
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	return release, nil
+}
+
+// limitTransport is an http.RoundTripper that waits for a requestLimiter
+// before sending each request.
+type limitTransport struct {
+	limiter *requestLimiter
+	base    http.RoundTripper
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := t.limiter.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return t.base.RoundTrip(req)
+}
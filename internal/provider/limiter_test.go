@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestLimiterCapsConcurrency(t *testing.T) {
+	limiter := newRequestLimiter(0, 2)
+
+	var active, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			release, err := limiter.acquire(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer release()
+
+			n := atomic.AddInt32(&active, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&active, -1)
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Fatalf("expected at most 2 concurrent requests, got %d", peak)
+	}
+}
+
+func TestRequestLimiterRate(t *testing.T) {
+	limiter := newRequestLimiter(20, 0)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		release, err := limiter.acquire(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+
+	// a burst of one second's worth of requests is allowed immediately
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the burst to be available immediately, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 20; i++ {
+		release, err := limiter.acquire(ctx)
+		if err != nil {
+			return
+		}
+		release()
+	}
+	t.Fatal("expected the rate limit to block until the context expired")
+}
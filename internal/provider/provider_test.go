@@ -1,19 +1,31 @@
 package provider
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/umich-vci/gorhsm"
 )
 
 func testAccPreCheck(t *testing.T) {
 	refreshToken := os.Getenv("RHSM_REFRESH_TOKEN")
+	clientID := os.Getenv("RHSM_CLIENT_ID")
 
-	if refreshToken == "" {
-		t.Fatalf("RHSM_REFRESH_TOKEN must be set for acceptance tests to run")
+	if refreshToken == "" && clientID == "" {
+		t.Fatalf("RHSM_REFRESH_TOKEN or RHSM_CLIENT_ID and RHSM_CLIENT_SECRET must be set for acceptance tests to run")
 	}
 }
 
@@ -23,6 +35,51 @@ func protoV6ProviderFactories() map[string]func() (tfprotov6.ProviderServer, err
 	}
 }
 
+// testAPIClient returns a client for a local stand-in for the RHSM API that
+// is served by handler.
+func testAPIClient(t *testing.T, handler http.Handler) *apiClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	rhsmConfig := gorhsm.NewConfiguration()
+	rhsmConfig.Servers = gorhsm.ServerConfigurations{{URL: server.URL}}
+	rhsmConfig.HTTPClient = server.Client()
+
+	client := gorhsm.NewAPIClient(rhsmConfig)
+
+	return &apiClient{
+		Client:      client,
+		CloudAccess: newCloudAccessCache(client),
+	}
+}
+
+// testPlan returns a plan for r with the values in model. A Timeouts field
+// that is left unset is planned as a null timeouts block.
+func testPlan[M any](t *testing.T, r fwresource.Resource, model *M) tfsdk.Plan {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+	if field := reflect.ValueOf(model).Elem().FieldByName("Timeouts"); field.IsValid() && field.IsZero() {
+		timeoutsType := schemaResp.Schema.Blocks["timeouts"].Type().(attr.TypeWithAttributeTypes)
+		field.Set(reflect.ValueOf(timeouts.Value{Object: types.ObjectNull(timeoutsType.AttributeTypes())}))
+	}
+
+	plan := tfsdk.Plan{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+	}
+	if diags := plan.Set(ctx, model); diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	return plan
+}
+
 func TestProvider(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: protoV6ProviderFactories(),
@@ -33,3 +90,100 @@ func TestProvider(t *testing.T) {
 		},
 	})
 }
+
+func TestValidateEndpointURL(t *testing.T) {
+	valid := []string{
+		"https://api.access.redhat.com/management/v1",
+		"http://127.0.0.1:8080",
+	}
+	for _, v := range valid {
+		if err := validateEndpointURL(v); err != nil {
+			t.Errorf("expected %q to be valid, got %s", v, err)
+		}
+	}
+
+	invalid := []string{
+		"api.access.redhat.com/management/v1",
+		"ftp://example.com",
+		"https://",
+		"://example.com",
+	}
+	for _, v := range invalid {
+		if err := validateEndpointURL(v); err == nil {
+			t.Errorf("expected %q to be invalid", v)
+		}
+	}
+}
+
+func TestProviderDefersUnknownCredentials(t *testing.T) {
+	ctx := context.Background()
+
+	server, err := providerserver.NewProtocol6WithError(New("test"))()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configType := schemaResp.Provider.ValueType()
+	attributes := map[string]tftypes.Value{}
+	for name, attrType := range configType.(tftypes.Object).AttributeTypes {
+		attributes[name] = tftypes.NewValue(attrType, nil)
+	}
+	attributes["refresh_token"] = tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+
+	config, err := tfprotov6.NewDynamicValue(configType, tftypes.NewValue(configType, attributes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{
+		Config:             &config,
+		ClientCapabilities: &tfprotov6.ConfigureProviderClientCapabilities{DeferralAllowed: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics[0].Detail)
+	}
+
+	dataSourceType := schemaResp.DataSourceSchemas["rhsm_cloud_access"].ValueType()
+	dataSourceConfig, err := tfprotov6.NewDynamicValue(dataSourceType, tftypes.NewValue(dataSourceType, map[string]tftypes.Value{
+		"enabled_accounts": tftypes.NewValue(dataSourceType.(tftypes.Object).AttributeTypes["enabled_accounts"], nil),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readResp, err := server.ReadDataSource(ctx, &tfprotov6.ReadDataSourceRequest{
+		TypeName:           "rhsm_cloud_access",
+		Config:             &dataSourceConfig,
+		ClientCapabilities: &tfprotov6.ReadDataSourceClientCapabilities{DeferralAllowed: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readResp.Deferred == nil || readResp.Deferred.Reason != tfprotov6.DeferredReasonProviderConfigUnknown {
+		t.Fatalf("expected the data source to be deferred, got %v", readResp.Deferred)
+	}
+
+	// Terraform versions without deferred actions still get an error
+	server, err = providerserver.NewProtocol6WithError(New("test"))()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: &config})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Diagnostics) == 0 {
+		t.Fatal("expected an error when deferral is not allowed")
+	}
+}
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/umich-vci/gorhsm"
+)
+
+// quotaWarning is a capacity problem found with the products enabled for a
+// cloud provider.
+type quotaWarning struct {
+	summary string
+	detail  string
+	// account is true when the warning is about adding the account rather
+	// than one of its gold images
+	account bool
+}
+
+// checkCloudAccessQuota returns warnings for an account that is about to be
+// added to a cloud provider, or given access to the added gold images, when
+// that would go over the enabled quantity of a product, or when no product
+// enabled for the cloud provider covers the image group. A new account counts
+// against every product of the cloud provider, and an existing account uses
+// a product when it has access to one of the product's image groups.
+func checkCloudAccessQuota(caps *gorhsm.ListEnabledCloudAccessProviders200Response, shortName string, accountID string, added []string) []quotaWarning {
+	if caps == nil {
+		return nil
+	}
+
+	var provider *gorhsm.EnabledCloudAccessProvider
+	for _, x := range caps.GetBody() {
+		if x.GetShortName() == shortName {
+			provider = &x
+			break
+		}
+	}
+	if provider == nil {
+		return nil
+	}
+
+	var warnings []quotaWarning
+	var checked []string
+
+	if findProviderAccount(caps, shortName, accountID) == nil {
+		used := len(provider.GetAccounts())
+
+		for _, product := range provider.GetProducts() {
+			if used < int(product.GetEnabledQuantity()) {
+				continue
+			}
+			checked = append(checked, product.GetSku())
+
+			warnings = append(warnings, quotaWarning{
+				summary: "Cloud Access product quota exceeded",
+				detail: fmt.Sprintf("Adding account %s would use the %s product (SKU %s) in more accounts than its enabled quantity allows. "+
+					"%d accounts are already enabled for %s, and the enabled quantity is %d out of a total quantity of %d. "+
+					"Enable more of the product for the cloud provider in the Red Hat Hybrid Cloud Console before applying.",
+					accountID, product.GetName(), product.GetSku(),
+					used, shortName, product.GetEnabledQuantity(), product.GetTotalQuantity()),
+				account: true,
+			})
+		}
+	}
+
+	for _, image := range added {
+		var covered bool
+
+		for _, product := range provider.GetProducts() {
+			if !slices.Contains(product.GetImageGroups(), image) {
+				continue
+			}
+			covered = true
+
+			if slices.Contains(checked, product.GetSku()) {
+				continue
+			}
+			checked = append(checked, product.GetSku())
+
+			used := 0
+			inUse := false
+			for _, account := range provider.GetAccounts() {
+				if !accountUsesProduct(account, product) {
+					continue
+				}
+				if account.Id == accountID {
+					inUse = true
+					break
+				}
+				used++
+			}
+
+			// the account already uses the product, so it needs no more of it
+			if inUse || used < int(product.GetEnabledQuantity()) {
+				continue
+			}
+
+			warnings = append(warnings, quotaWarning{
+				summary: "Cloud Access product quota exceeded",
+				detail: fmt.Sprintf("Giving account %s access to the %s gold image would use the %s product (SKU %s) in more accounts than its enabled quantity allows. "+
+					"%d of %d are already in use for %s, out of a total quantity of %d. "+
+					"Enable more of the product for the cloud provider in the Red Hat Hybrid Cloud Console before applying.",
+					accountID, image, product.GetName(), product.GetSku(),
+					used, product.GetEnabledQuantity(), shortName, product.GetTotalQuantity()),
+			})
+		}
+
+		if !covered {
+			skus := make([]string, 0, len(provider.GetProducts()))
+			for _, product := range provider.GetProducts() {
+				skus = append(skus, product.GetSku())
+			}
+
+			warnings = append(warnings, quotaWarning{
+				summary: "Gold image not covered by a Cloud Access product",
+				detail: fmt.Sprintf("No product enabled for %s includes the %s image group, so the request for it is likely to fail. "+
+					"The products enabled for %s are: %s.", shortName, image, shortName, quoteList(skus)),
+			})
+		}
+	}
+
+	return warnings
+}
+
+// accountUsesProduct returns true if account has access to, or has requested,
+// one of the image groups of product.
+func accountUsesProduct(account gorhsm.EnabledProviderAccount, product gorhsm.EnabledProduct) bool {
+	for _, status := range account.GetGoldImageStatus() {
+		if strings.EqualFold(status.GetStatus(), goldImageStatusFailed) {
+			continue
+		}
+		if slices.Contains(product.GetImageGroups(), status.GetName()) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/umich-vci/gorhsm"
+)
+
+func TestCheckCloudAccessQuota(t *testing.T) {
+	granted := func(images ...string) []gorhsm.GoldImageStatus {
+		statuses := make([]gorhsm.GoldImageStatus, len(images))
+		for i, image := range images {
+			statuses[i] = gorhsm.GoldImageStatus{Name: gorhsm.PtrString(image), Status: gorhsm.PtrString("Granted")}
+		}
+		return statuses
+	}
+
+	caps := &gorhsm.ListEnabledCloudAccessProviders200Response{
+		Body: []gorhsm.EnabledCloudAccessProvider{{
+			ShortName: gorhsm.PtrString("AWS"),
+			Products: []gorhsm.EnabledProduct{
+				{
+					Name:            gorhsm.PtrString("Red Hat Enterprise Linux Server"),
+					Sku:             gorhsm.PtrString("RH00003"),
+					ImageGroups:     []string{"rhel"},
+					EnabledQuantity: gorhsm.PtrInt32(2),
+					TotalQuantity:   gorhsm.PtrInt32(10),
+				},
+				{
+					Name:            gorhsm.PtrString("Red Hat Enterprise Linux High Availability"),
+					Sku:             gorhsm.PtrString("RH00025"),
+					ImageGroups:     []string{"rhel-ha"},
+					EnabledQuantity: gorhsm.PtrInt32(4),
+					TotalQuantity:   gorhsm.PtrInt32(4),
+				},
+			},
+			Accounts: []gorhsm.EnabledProviderAccount{
+				{Id: "111111111111", GoldImageStatus: granted("rhel", "rhel-ha")},
+				{Id: "222222222222", GoldImageStatus: granted("rhel")},
+				{Id: "333333333333", GoldImageStatus: []gorhsm.GoldImageStatus{
+					{Name: gorhsm.PtrString("rhel-ha"), Status: gorhsm.PtrString("Failed")},
+				}},
+			},
+		}},
+	}
+
+	cases := map[string]struct {
+		accountID string
+		added     []string
+		expect    []string
+	}{
+		"within quota": {
+			accountID: "333333333333",
+			added:     []string{"rhel-ha"},
+		},
+		"over quota": {
+			accountID: "333333333333",
+			added:     []string{"rhel"},
+			expect:    []string{"RH00003"},
+		},
+		"already using product": {
+			accountID: "111111111111",
+			added:     []string{"rhel"},
+		},
+		// three accounts are enabled, which is over the quantity of RH00003
+		"new account": {
+			accountID: "444444444444",
+			expect:    []string{"RH00003"},
+		},
+		"new account with image": {
+			accountID: "444444444444",
+			added:     []string{"rhel-ha"},
+			expect:    []string{"RH00003"},
+		},
+		"not covered": {
+			accountID: "333333333333",
+			added:     []string{"rhel-sap"},
+			expect:    []string{"rhel-sap"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			warnings := checkCloudAccessQuota(caps, "AWS", c.accountID, c.added)
+			if len(warnings) != len(c.expect) {
+				t.Fatalf("expected %d warnings, got %v", len(c.expect), warnings)
+			}
+
+			for i, w := range warnings {
+				if !strings.Contains(w.detail, c.expect[i]) {
+					t.Errorf("expected warning to name %s, got %q", c.expect[i], w.detail)
+				}
+			}
+		})
+	}
+}
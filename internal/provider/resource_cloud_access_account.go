@@ -3,19 +3,24 @@ package provider
 import (
 	"context"
 	"fmt"
-	"io"
+	"slices"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/umich-vci/gorhsm"
@@ -24,6 +29,10 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &CloudAccessAccountResource{}
 var _ resource.ResourceWithImportState = &CloudAccessAccountResource{}
+var _ resource.ResourceWithModifyPlan = &CloudAccessAccountResource{}
+var _ resource.ResourceWithConfigValidators = &CloudAccessAccountResource{}
+var _ resource.ResourceWithIdentity = &CloudAccessAccountResource{}
+var _ resource.ResourceWithUpgradeState = &CloudAccessAccountResource{}
 
 func NewCloudAccessAccountResource() resource.Resource {
 	return &CloudAccessAccountResource{}
@@ -36,15 +45,27 @@ type CloudAccessAccountResource struct {
 
 // CloudAccessAccountResourceModel describes the resource data model.
 type CloudAccessAccountResourceModel struct {
-	ID                types.String `tfsdk:"id"`
-	AccountID         types.String `tfsdk:"account_id"`
+	ID                 types.String   `tfsdk:"id"`
+	AccountID          types.String   `tfsdk:"account_id"`
+	ProviderShortName  types.String   `tfsdk:"provider_short_name"`
+	GoldImages         types.Set      `tfsdk:"gold_images"`
+	GoldImagesRemoval  types.String   `tfsdk:"gold_images_removal"`
+	AdoptExisting      types.Bool     `tfsdk:"adopt_existing"`
+	DeletionProtection types.Bool     `tfsdk:"deletion_protection"`
+	RetainOnDestroy    types.Bool     `tfsdk:"retain_on_destroy"`
+	WaitForGoldImages  types.Bool     `tfsdk:"wait_for_gold_images"`
+	Nickname           types.String   `tfsdk:"nickname"`
+	DateAdded          types.String   `tfsdk:"date_added"`
+	GoldImageStatus    types.Set      `tfsdk:"gold_image_status"`
+	SourceID           types.String   `tfsdk:"source_id"`
+	Verified           types.Bool     `tfsdk:"verified"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
+}
+
+// CloudAccessAccountIdentityModel describes the resource identity data model.
+type CloudAccessAccountIdentityModel struct {
 	ProviderShortName types.String `tfsdk:"provider_short_name"`
-	GoldImages        types.Set    `tfsdk:"gold_images"`
-	Nickname          types.String `tfsdk:"nickname"`
-	DateAdded         types.String `tfsdk:"date_added"`
-	GoldImageStatus   types.Set    `tfsdk:"gold_image_status"`
-	SourceID          types.String `tfsdk:"source_id"`
-	Verified          types.Bool   `tfsdk:"verified"`
+	AccountID         types.String `tfsdk:"account_id"`
 }
 
 type GoldImageStatusModel struct {
@@ -68,6 +89,7 @@ func (r *CloudAccessAccountResource) Metadata(ctx context.Context, req resource.
 func (r *CloudAccessAccountResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "Resource to manage entitlement for Red Hat Cloud Access for an account in a supported cloud provider.",
+		Version:             cloudAccessAccountSchemaVersion,
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -78,7 +100,7 @@ func (r *CloudAccessAccountResource) Schema(ctx context.Context, req resource.Sc
 				},
 			},
 			"account_id": schema.StringAttribute{
-				Description: "The ID of a cloud account that you would like to request Red Hat Cloud Access for. For GCE this should be a Google Group.",
+				Description: "The ID of a cloud account that you would like to request Red Hat Cloud Access for. For AWS this must be a 12 digit account ID, for MSAZ a subscription ID in GUID format, and for GCE the email address of a Google Group. Planning warns when adding the account would enable more accounts for the cloud provider than the enabled quantity of one of its products.",
 				Required:    true,
 				Validators:  []validator.String{stringvalidator.NoneOf("")},
 				PlanModifiers: []planmodifier.String{
@@ -86,20 +108,51 @@ func (r *CloudAccessAccountResource) Schema(ctx context.Context, req resource.Sc
 				},
 			},
 			"provider_short_name": schema.StringAttribute{
-				Description: "The short name of the cloud provider that the `account_id` is in. This must be one of \"AWS\", \"GCE\", or \"MSAZ\".  Other cloud providers are supported but have not been tested so they are not in the list of valid options.",
+				Description: "The short name of the cloud provider that the `account_id` is in, such as \"AWS\", \"GCE\", or \"MSAZ\". This must be one of the cloud providers enabled for Cloud Access in your organization, which can be found with the `rhsm_cloud_access` data source.",
 				Required:    true,
-				Validators:  []validator.String{stringvalidator.OneOf(cloudAccessAccountProviders...)},
+				Validators:  []validator.String{stringvalidator.NoneOf("")},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"gold_images": schema.SetAttribute{
-				Description: "A list of gold images to request access to for the account. Images available to a cloud provider can be found with the `rhsm_cloud_access` data source. Once you request access to a gold image, it is not possible to disable access via the API.",
+				Description: "A list of gold images to request access to for the account. Images available to a cloud provider can be found with the `rhsm_cloud_access` data source. Once you request access to a gold image, it is not possible to disable access via the API. If this is not set, the gold images of the account are not managed by this resource, so they can be managed with `rhsm_cloud_access_gold_image` instead. Planning warns when a gold image is not covered by a product enabled for the cloud provider, or when adding it would use a product in more accounts than its enabled quantity.",
 				Optional:    true,
 				Computed:    true,
 				Default:     setdefault.StaticValue(types.SetValueMust(types.StringType, []attr.Value{})),
 				ElementType: types.StringType,
 			},
+			"adopt_existing": schema.BoolAttribute{
+				Description: "Take ownership of the account if it is already enabled for Cloud Access, for example after it was added in the Red Hat Hybrid Cloud Console, instead of failing. The nickname is updated and any missing gold images are requested to match the configuration. Defaults to `false`.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"deletion_protection": schema.BoolAttribute{
+				Description: "Prevent the account from being destroyed or replaced. While this is `true`, any plan that would remove the account from Cloud Access fails, so it must be set to `false` and applied before the account can be destroyed. Defaults to `false`.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"retain_on_destroy": schema.BoolAttribute{
+				Description: "Only remove the account from the Terraform state when it is destroyed, leaving it enabled for Cloud Access in RHSM. Defaults to `false`.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"gold_images_removal": schema.StringAttribute{
+				Description: "What to do when images are removed from `gold_images`. Access to a gold image cannot be removed through the API, so removed images are kept in the plan when no image is added in the same change. This must be \"warn\" to show a warning or \"error\" to fail the plan. Defaults to \"warn\".",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(goldImagesRemovalWarn),
+				Validators:  []validator.String{stringvalidator.OneOf(goldImagesRemovalWarn, goldImagesRemovalError)},
+			},
+			"wait_for_gold_images": schema.BoolAttribute{
+				Description: "Wait for every gold image request to be granted or to fail before finishing. A gold image request that fails causes the apply to fail. Defaults to `false`.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
 			"nickname": schema.StringAttribute{
 				Description: "A nickname to help describe the account.",
 				Optional:    true,
@@ -137,6 +190,36 @@ func (r *CloudAccessAccountResource) Schema(ctx context.Context, req resource.Sc
 				Computed:    true,
 			},
 		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+func (r *CloudAccessAccountResource) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"provider_short_name": identityschema.StringAttribute{
+				Description:       "The short name of the cloud provider that the `account_id` is in, such as \"AWS\", \"GCE\", or \"MSAZ\".",
+				RequiredForImport: true,
+			},
+			"account_id": identityschema.StringAttribute{
+				Description:       "The ID of the account in the cloud provider.",
+				RequiredForImport: true,
+			},
+		},
+	}
+}
+
+func (r *CloudAccessAccountResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		accountIDValidator{},
 	}
 }
 
@@ -165,67 +248,143 @@ func (r *CloudAccessAccountResource) Create(ctx context.Context, req resource.Cr
 		return
 	}
 
+	createTimeout, diags := data.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	client := r.client.Client
-	auth := r.client.Auth
+
+	ctx = tflog.SetField(ctx, "provider_short_name", data.ProviderShortName.ValueString())
+	ctx = tflog.SetField(ctx, "account_id", data.AccountID.ValueString())
 
 	data.ID = types.StringValue(fmt.Sprintf("%s:%s", data.ProviderShortName.ValueString(), data.AccountID.ValueString()))
 
-	account := &gorhsm.AddProviderAccount{
-		Id:       data.AccountID.ValueStringPointer(),
-		Nickname: data.Nickname.ValueStringPointer(),
+	var goldImages []string
+	resp.Diagnostics.Append(data.GoldImages.ElementsAs(ctx, &goldImages, false)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	accountList := []gorhsm.AddProviderAccount{*account}
 
-	apa, err := client.CloudaccessAPI.AddProviderAccounts(auth, data.ProviderShortName.ValueString()).Account(accountList).Execute()
-	if apa != nil {
-		defer apa.Body.Close()
-	}
-	if err != nil {
-		apaBody, e := io.ReadAll(apa.Body)
-		if e != nil && apaBody != nil {
-			resp.Diagnostics.AddError("Failed to create Cloud Access Account", err.Error())
+	var existing *CloudAccessAccountModel
+	if data.AdoptExisting.ValueBool() {
+		// make sure an account added outside of Terraform is seen
+		r.client.CloudAccess.invalidate()
 
-		} else {
-			resp.Diagnostics.AddError("Failed to create Cloud Access Account", string(apaBody))
+		existing, diags = readCloudAccessAccount(ctx, r.client, data.ProviderShortName.ValueString(), data.AccountID.ValueString())
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
-		return
 	}
 
-	var goldImages []string
-	data.GoldImages.ElementsAs(ctx, &goldImages, false)
+	requestedImages := goldImages
+	if existing != nil {
+		tflog.Info(ctx, "Adopting existing cloud access account")
+
+		if !data.Nickname.Equal(nicknameValue(existing.Nickname.ValueString(), data.Nickname)) {
+			account := &gorhsm.UpdateProviderAccountRequest{Nickname: data.Nickname.ValueString()}
+			upa, err := client.CloudaccessAPI.UpdateProviderAccount(ctx, data.ProviderShortName.ValueString(), data.AccountID.ValueString()).Account(*account).Execute()
+			r.client.CloudAccess.invalidate()
+			if err != nil {
+				if addTimeoutError(ctx, &resp.Diagnostics, "update the account nickname") {
+					return
+				}
+				addAPIError(&resp.Diagnostics, "Failed to update Cloud Access Account nickname", upa, err)
+				return
+			}
+		}
+
+		// only request the images the account does not already have
+		var enabledImages []string
+		resp.Diagnostics.Append(existing.GoldImages.ElementsAs(ctx, &enabledImages, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		requestedImages = nil
+		for _, image := range goldImages {
+			if !slices.Contains(enabledImages, image) {
+				requestedImages = append(requestedImages, image)
+			}
+		}
+	} else {
+		account := &gorhsm.AddProviderAccount{
+			Id:       data.AccountID.ValueStringPointer(),
+			Nickname: data.Nickname.ValueStringPointer(),
+		}
+		accountList := []gorhsm.AddProviderAccount{*account}
+
+		apa, err := client.CloudaccessAPI.AddProviderAccounts(ctx, data.ProviderShortName.ValueString()).Account(accountList).Execute()
+		r.client.CloudAccess.invalidate()
+		if apa != nil {
+			defer apa.Body.Close()
+		}
+		if err != nil && requestOutcomeUnknown(apa, err) {
+			// the account may have been added before the connection failed, so
+			// check for it instead of failing or adding it a second time
+			if cloudAccessAccountEnabled(ctx, r.client, data.ProviderShortName.ValueString(), data.AccountID.ValueString()) {
+				tflog.Debug(ctx, "cloud access account was added despite an error response", map[string]interface{}{"error": err.Error()})
+				err = nil
+			}
+		}
+		if err != nil {
+			if addTimeoutError(ctx, &resp.Diagnostics, "add the cloud access account") {
+				return
+			}
+			addAPIError(&resp.Diagnostics, "Failed to create Cloud Access Account", apa, err)
+
+			// point to the ways of managing an account that already exists
+			if cloudAccessAccountEnabled(ctx, r.client, data.ProviderShortName.ValueString(), data.AccountID.ValueString()) {
+				resp.Diagnostics.AddError("Cloud Access Account already exists",
+					fmt.Sprintf("The account %s is already enabled for Cloud Access. "+
+						"Set adopt_existing to true to manage it with this resource, or import it with the ID %s.", data.AccountID.ValueString(), data.ID.ValueString()))
+			}
+			return
+		}
+	}
 
 	// do not enable gold images if none are specified
-	if len(goldImages) > 0 {
+	if len(requestedImages) > 0 {
 		gi := &gorhsm.EnableGoldImagesRequest{
 			Accounts: []string{data.AccountID.ValueString()},
-			Images:   goldImages,
+			Images:   requestedImages,
 		}
 
-		egi, err := client.CloudaccessAPI.EnableGoldImages(auth, data.ProviderShortName.ValueString()).GoldImages(*gi).Execute()
+		egi, err := client.CloudaccessAPI.EnableGoldImages(ctx, data.ProviderShortName.ValueString()).GoldImages(*gi).Execute()
+		r.client.CloudAccess.invalidate()
 		if egi != nil {
 			defer egi.Body.Close()
 		}
+		if err != nil && requestOutcomeUnknown(egi, err) && goldImagesRequested(ctx, r.client, data.ProviderShortName.ValueString(), data.AccountID.ValueString(), requestedImages) {
+			tflog.Debug(ctx, "gold images were requested despite an error response", map[string]interface{}{"error": err.Error()})
+			err = nil
+		}
 		if err != nil {
-			egiBody, e := io.ReadAll(egi.Body)
-			if e != nil && egiBody != nil {
-				resp.Diagnostics.AddError("Failed to enable gold images", err.Error())
-			} else {
-				resp.Diagnostics.AddError("Failed to enable gold images", string(egiBody))
+			if addTimeoutError(ctx, &resp.Diagnostics, "enable gold images") {
+				return
 			}
+			addAPIError(&resp.Diagnostics, "Failed to enable gold images", egi, err)
 			return
 		}
 	}
-	caps, capsRaw, err := client.CloudaccessAPI.ListEnabledCloudAccessProviders(auth).Execute()
-	if capsRaw != nil {
-		defer capsRaw.Body.Close()
+
+	// the account exists now, so it is saved to state even if waiting fails
+	var waitDiags diag.Diagnostics
+	if data.WaitForGoldImages.ValueBool() && len(goldImages) > 0 {
+		waitDiags = waitForGoldImages(ctx, r.client, data.ProviderShortName.ValueString(), data.AccountID.ValueString(), goldImages)
 	}
+
+	caps, lecap, err := r.client.CloudAccess.get(ctx)
 	if err != nil {
-		capsBody, e := io.ReadAll(capsRaw.Body)
-		if e != nil && capsBody != nil {
-			resp.Diagnostics.AddError("Failed to list enabled cloud access providers", err.Error())
-		} else {
-			resp.Diagnostics.AddError("Failed to list enabled cloud access providers", string(capsBody))
+		if addTimeoutError(ctx, &resp.Diagnostics, "list enabled cloud access providers") {
+			return
 		}
+		addAPIError(&resp.Diagnostics, "Failed to list enabled cloud access providers", lecap, err)
 		return
 	}
 
@@ -243,11 +402,15 @@ func (r *CloudAccessAccountResource) Create(ctx context.Context, req resource.Cr
 
 	data.AccountID = caa.AccountID
 	data.ProviderShortName = caa.ProviderShortName
-	data.Nickname = caa.Nickname
+	data.Nickname = nicknameValue(caa.Nickname.ValueString(), data.Nickname)
 	data.DateAdded = caa.DateAdded
 	data.SourceID = caa.SourceID
 	data.Verified = caa.Verified
-	data.GoldImages = caa.GoldImages
+	// an adopted account may have more gold images than were planned, and
+	// access to those cannot be removed
+	if existing == nil {
+		data.GoldImages = caa.GoldImages
+	}
 	data.GoldImageStatus = caa.GoldImageStatus
 
 	// Write logs using the tflog package
@@ -256,6 +419,8 @@ func (r *CloudAccessAccountResource) Create(ctx context.Context, req resource.Cr
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setCloudAccessAccountIdentity(ctx, resp.Identity, data)...)
+	resp.Diagnostics.Append(waitDiags...)
 }
 
 func (r *CloudAccessAccountResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -268,8 +433,14 @@ func (r *CloudAccessAccountResource) Read(ctx context.Context, req resource.Read
 		return
 	}
 
-	client := r.client.Client
-	auth := r.client.Auth
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
 
 	shortName, accountID, err := resourceCloudAccessAccountSplitID(data.ID.ValueString())
 	if err != nil {
@@ -277,9 +448,15 @@ func (r *CloudAccessAccountResource) Read(ctx context.Context, req resource.Read
 		return
 	}
 
-	caps, _, err := client.CloudaccessAPI.ListEnabledCloudAccessProviders(auth).Execute()
+	ctx = tflog.SetField(ctx, "provider_short_name", shortName)
+	ctx = tflog.SetField(ctx, "account_id", accountID)
+
+	caps, lecap, err := r.client.CloudAccess.get(ctx)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to list enabled cloud access providers", err.Error())
+		if addTimeoutError(ctx, &resp.Diagnostics, "list enabled cloud access providers") {
+			return
+		}
+		addAPIError(&resp.Diagnostics, "Failed to list enabled cloud access providers", lecap, err)
 		return
 	}
 
@@ -289,7 +466,8 @@ func (r *CloudAccessAccountResource) Read(ctx context.Context, req resource.Read
 		return
 	}
 
-	// no matching account was found and no error was returned
+	// no matching account was found, or its cloud provider is no longer
+	// enabled, and no error was returned
 	if caa == nil {
 		resp.State.RemoveResource(ctx)
 		return
@@ -297,15 +475,33 @@ func (r *CloudAccessAccountResource) Read(ctx context.Context, req resource.Read
 
 	data.AccountID = caa.AccountID
 	data.ProviderShortName = caa.ProviderShortName
-	data.Nickname = caa.Nickname
+	data.Nickname = nicknameValue(caa.Nickname.ValueString(), data.Nickname)
 	data.DateAdded = caa.DateAdded
 	data.SourceID = caa.SourceID
 	data.Verified = caa.Verified
 	data.GoldImages = caa.GoldImages
 	data.GoldImageStatus = caa.GoldImageStatus
 
+	// not returned by the API, so these are unset after an import
+	if data.AdoptExisting.IsNull() {
+		data.AdoptExisting = types.BoolValue(false)
+	}
+	if data.DeletionProtection.IsNull() {
+		data.DeletionProtection = types.BoolValue(false)
+	}
+	if data.RetainOnDestroy.IsNull() {
+		data.RetainOnDestroy = types.BoolValue(false)
+	}
+	if data.GoldImagesRemoval.IsNull() {
+		data.GoldImagesRemoval = types.StringValue(goldImagesRemovalWarn)
+	}
+	if data.WaitForGoldImages.IsNull() {
+		data.WaitForGoldImages = types.BoolValue(false)
+	}
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setCloudAccessAccountIdentity(ctx, resp.Identity, data)...)
 }
 
 func (r *CloudAccessAccountResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -319,8 +515,16 @@ func (r *CloudAccessAccountResource) Update(ctx context.Context, req resource.Up
 		return
 	}
 
+	updateTimeout, diags := data.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	client := r.client.Client
-	auth := r.client.Auth
 
 	shortName, accountID, err := resourceCloudAccessAccountSplitID(data.ID.ValueString())
 	if err != nil {
@@ -328,37 +532,61 @@ func (r *CloudAccessAccountResource) Update(ctx context.Context, req resource.Up
 		return
 	}
 
+	ctx = tflog.SetField(ctx, "provider_short_name", shortName)
+	ctx = tflog.SetField(ctx, "account_id", accountID)
+
 	if !data.Nickname.Equal(state.Nickname) {
 		account := &gorhsm.UpdateProviderAccountRequest{Nickname: data.Nickname.ValueString()}
-		_, err := client.CloudaccessAPI.UpdateProviderAccount(auth, shortName, accountID).Account(*account).Execute()
+		upa, err := client.CloudaccessAPI.UpdateProviderAccount(ctx, shortName, accountID).Account(*account).Execute()
+		r.client.CloudAccess.invalidate()
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to update Cloud Access Account nickname", err.Error())
+			if addTimeoutError(ctx, &resp.Diagnostics, "update the account nickname") {
+				return
+			}
+			addAPIError(&resp.Diagnostics, "Failed to update Cloud Access Account nickname", upa, err)
 			return
 		}
 	}
 
+	goldImages := []string{}
+	resp.Diagnostics.Append(data.GoldImages.ElementsAs(ctx, &goldImages, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	if !data.GoldImages.Equal(state.GoldImages) {
-		goldImages := []string{}
-		resp.Diagnostics.Append(data.GoldImages.ElementsAs(ctx, goldImages, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
 
 		gi := &gorhsm.EnableGoldImagesRequest{
 			Accounts: []string{accountID},
 			Images:   goldImages,
 		}
 
-		_, err := client.CloudaccessAPI.EnableGoldImages(auth, shortName).GoldImages(*gi).Execute()
+		egi, err := client.CloudaccessAPI.EnableGoldImages(ctx, shortName).GoldImages(*gi).Execute()
+		r.client.CloudAccess.invalidate()
+		if err != nil && requestOutcomeUnknown(egi, err) && goldImagesRequested(ctx, r.client, shortName, accountID, goldImages) {
+			tflog.Debug(ctx, "gold images were requested despite an error response", map[string]interface{}{"error": err.Error()})
+			err = nil
+		}
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to enable gold images", err.Error())
+			if addTimeoutError(ctx, &resp.Diagnostics, "enable gold images") {
+				return
+			}
+			addAPIError(&resp.Diagnostics, "Failed to enable gold images", egi, err)
 			return
 		}
 	}
 
-	caps, _, err := client.CloudaccessAPI.ListEnabledCloudAccessProviders(auth).Execute()
+	var waitDiags diag.Diagnostics
+	if data.WaitForGoldImages.ValueBool() && len(goldImages) > 0 {
+		waitDiags = waitForGoldImages(ctx, r.client, shortName, accountID, goldImages)
+	}
+
+	caps, lecap, err := r.client.CloudAccess.get(ctx)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to list enabled cloud access providers", err.Error())
+		if addTimeoutError(ctx, &resp.Diagnostics, "list enabled cloud access providers") {
+			return
+		}
+		addAPIError(&resp.Diagnostics, "Failed to list enabled cloud access providers", lecap, err)
 		return
 	}
 
@@ -376,15 +604,18 @@ func (r *CloudAccessAccountResource) Update(ctx context.Context, req resource.Up
 
 	data.AccountID = caa.AccountID
 	data.ProviderShortName = caa.ProviderShortName
-	data.Nickname = caa.Nickname
+	data.Nickname = nicknameValue(caa.Nickname.ValueString(), data.Nickname)
 	data.DateAdded = caa.DateAdded
 	data.SourceID = caa.SourceID
 	data.Verified = caa.Verified
-	data.GoldImages = caa.GoldImages
+	// gold_images is left as planned since images that were removed from it
+	// are still returned by the API
 	data.GoldImageStatus = caa.GoldImageStatus
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setCloudAccessAccountIdentity(ctx, resp.Identity, data)...)
+	resp.Diagnostics.Append(waitDiags...)
 }
 
 func (r *CloudAccessAccountResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -397,8 +628,30 @@ func (r *CloudAccessAccountResource) Delete(ctx context.Context, req resource.De
 		return
 	}
 
+	deleteTimeout, diags := data.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	// the plan already fails for a protected account, but the state may have
+	// been changed since
+	if data.DeletionProtection.ValueBool() {
+		resp.Diagnostics.AddError("Cloud Access Account is protected from deletion",
+			fmt.Sprintf("The account %s has deletion_protection set. Set it to false and apply before destroying the account.", data.ID.ValueString()))
+		return
+	}
+
+	if data.RetainOnDestroy.ValueBool() {
+		tflog.Debug(ctx, "retaining the cloud access account in RHSM")
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
 	client := r.client.Client
-	auth := r.client.Auth
 
 	shortName, accountID, err := resourceCloudAccessAccountSplitID(data.ID.ValueString())
 	if err != nil {
@@ -406,21 +659,302 @@ func (r *CloudAccessAccountResource) Delete(ctx context.Context, req resource.De
 		return
 	}
 
+	ctx = tflog.SetField(ctx, "provider_short_name", shortName)
+	ctx = tflog.SetField(ctx, "account_id", accountID)
+
 	remove := &gorhsm.RemoveProviderAccountRequest{
 		Id: accountID,
 	}
 
-	_, err = client.CloudaccessAPI.RemoveProviderAccount(auth, shortName).Account(*remove).Execute()
+	rpa, err := client.CloudaccessAPI.RemoveProviderAccount(ctx, shortName).Account(*remove).Execute()
+	r.client.CloudAccess.invalidate()
+	if err != nil && alreadyRemoved(rpa) {
+		tflog.Debug(ctx, "cloud access account was already removed", map[string]interface{}{"error": err.Error()})
+		err = nil
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to remove Cloud Access Account", err.Error())
+		if addTimeoutError(ctx, &resp.Diagnostics, "remove the cloud access account") {
+			return
+		}
+		addAPIError(&resp.Diagnostics, "Failed to remove Cloud Access Account", rpa, err)
 		return
 	}
 
 	resp.State.RemoveResource(ctx)
 }
 
+func (r *CloudAccessAccountResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !req.State.Raw.IsNull() {
+		r.modifyPlanDestroy(ctx, req, resp)
+	}
+
+	// nothing else to check when the resource is being destroyed
+	if req.Plan.Raw.IsNull() || resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan CloudAccessAccountResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state CloudAccessAccountResourceModel
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		var configGoldImages types.Set
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("gold_images"), &configGoldImages)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if configGoldImages.IsNull() {
+			// the gold images are not managed by this resource when
+			// gold_images is unset, such as when they are managed with
+			// rhsm_cloud_access_gold_image, so the images from the last
+			// refresh are kept
+			plan.GoldImages = state.GoldImages
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("gold_images"), state.GoldImages)...)
+		} else {
+			r.modifyPlanGoldImages(ctx, plan, state, resp)
+		}
+	}
+
+	// the provider has not been configured yet
+	if r.client == nil {
+		return
+	}
+
+	checkProviderShortName(ctx, r.client, plan.ProviderShortName, state.ProviderShortName, &resp.Diagnostics)
+	r.modifyPlanQuota(ctx, plan, state, resp)
+}
+
+// modifyPlanDestroy fails the plan when a protected account would be
+// destroyed or replaced, and warns when it will be left enabled in RHSM.
+func (r *CloudAccessAccountResource) modifyPlanDestroy(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the account is only destroyed if the resource is removed or replaced
+	if !req.Plan.Raw.IsNull() && len(resp.RequiresReplace) == 0 {
+		return
+	}
+
+	var state CloudAccessAccountResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.DeletionProtection.ValueBool() {
+		resp.Diagnostics.AddError("Cloud Access Account is protected from deletion",
+			fmt.Sprintf("The account %s has deletion_protection set, so it cannot be destroyed or replaced. "+
+				"Set deletion_protection to false and apply before destroying the account.", state.ID.ValueString()))
+		return
+	}
+
+	if state.RetainOnDestroy.ValueBool() {
+		resp.Diagnostics.AddWarning("Cloud Access Account will be retained",
+			fmt.Sprintf("The account %s has retain_on_destroy set, so it will only be removed from the Terraform state "+
+				"and will stay enabled for Cloud Access in RHSM.", state.ID.ValueString()))
+	}
+}
+
+// modifyPlanQuota warns when the account or the gold images being added to
+// it are not covered by a product enabled for the cloud provider, or would go
+// over the enabled quantity of a product.
+func (r *CloudAccessAccountResource) modifyPlanQuota(ctx context.Context, plan CloudAccessAccountResourceModel, state CloudAccessAccountResourceModel, resp *resource.ModifyPlanResponse) {
+	if !isKnown(plan.ProviderShortName) || !isKnown(plan.AccountID) || plan.GoldImages.IsUnknown() {
+		return
+	}
+
+	// the account is new when it is created or replaced by another account
+	newAccount := !plan.AccountID.Equal(state.AccountID) || !plan.ProviderShortName.Equal(state.ProviderShortName)
+
+	var planned, prior []string
+	resp.Diagnostics.Append(plan.GoldImages.ElementsAs(ctx, &planned, false)...)
+	if !newAccount && !state.GoldImages.IsNull() && !state.GoldImages.IsUnknown() {
+		resp.Diagnostics.Append(state.GoldImages.ElementsAs(ctx, &prior, false)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var added []string
+	for _, image := range planned {
+		if !slices.Contains(prior, image) {
+			added = append(added, image)
+		}
+	}
+	if !newAccount && len(added) == 0 {
+		return
+	}
+
+	caps, _, err := r.client.CloudAccess.get(ctx)
+	if err != nil {
+		// the check is best effort, and apply reports the API error
+		tflog.Debug(ctx, "skipping the Cloud Access quota check", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	for _, w := range checkCloudAccessQuota(caps, plan.ProviderShortName.ValueString(), plan.AccountID.ValueString(), added) {
+		if w.account {
+			resp.Diagnostics.AddAttributeWarning(path.Root("account_id"), w.summary, w.detail)
+			continue
+		}
+		resp.Diagnostics.AddAttributeWarning(path.Root("gold_images"), w.summary, w.detail)
+	}
+}
+
+// modifyPlanGoldImages reports gold images that were removed from the
+// configuration. Since access cannot be removed, the prior images are kept
+// in the plan when no new images were added so that the plan converges.
+func (r *CloudAccessAccountResource) modifyPlanGoldImages(ctx context.Context, plan CloudAccessAccountResourceModel, state CloudAccessAccountResourceModel, resp *resource.ModifyPlanResponse) {
+	if plan.GoldImages.IsUnknown() || state.GoldImages.IsNull() || state.GoldImages.IsUnknown() {
+		return
+	}
+
+	var planned, prior []string
+	resp.Diagnostics.Append(plan.GoldImages.ElementsAs(ctx, &planned, false)...)
+	resp.Diagnostics.Append(state.GoldImages.ElementsAs(ctx, &prior, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	removed, keepPrior := removedGoldImages(planned, prior)
+	if len(removed) == 0 {
+		return
+	}
+
+	summary := "Gold image access cannot be removed"
+	detail := fmt.Sprintf("Access to gold images cannot be removed through the RHSM API, so access to %s will remain enabled. "+
+		"Add the images back to gold_images to keep the configuration in sync with RHSM.", quoteList(removed))
+
+	if plan.GoldImagesRemoval.ValueString() == goldImagesRemovalError {
+		resp.Diagnostics.AddAttributeError(path.Root("gold_images"), summary, detail)
+		return
+	}
+
+	resp.Diagnostics.AddAttributeWarning(path.Root("gold_images"), summary, detail)
+
+	if keepPrior {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("gold_images"), state.GoldImages)...)
+	}
+}
+
+// ImportState imports an account by its identity, by an ID in the format
+// provider_short_name:account_id, or by a bare account ID, in which case the
+// cloud provider is looked up from the enabled accounts.
 func (r *CloudAccessAccountResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	id := req.ID
+
+	// imported with an identity in an import block (Terraform 1.12+)
+	if id == "" && req.Identity != nil {
+		var identity CloudAccessAccountIdentityModel
+		resp.Diagnostics.Append(req.Identity.Get(ctx, &identity)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		id = fmt.Sprintf("%s:%s", identity.ProviderShortName.ValueString(), identity.AccountID.ValueString())
+	}
+
+	// look up the cloud provider when only the account ID was given
+	if !strings.Contains(id, ":") {
+		if r.client == nil {
+			resp.Diagnostics.AddError("Failed to import Cloud Access Account", "The provider has not been configured.")
+			return
+		}
+
+		caps, lecap, err := r.client.CloudAccess.get(ctx)
+		if err != nil {
+			addAPIError(&resp.Diagnostics, "Failed to list enabled cloud access providers", lecap, err)
+			return
+		}
+
+		shortNames := findAccountProviders(caps, id)
+		switch len(shortNames) {
+		case 0:
+			resp.Diagnostics.AddError("Cloud Access Account not found",
+				fmt.Sprintf("No cloud provider has an account with the ID %s enabled for Cloud Access.", id))
+			return
+		case 1:
+			id = fmt.Sprintf("%s:%s", shortNames[0], id)
+		default:
+			resp.Diagnostics.AddError("Ambiguous Cloud Access Account ID",
+				fmt.Sprintf("The account ID %s is enabled for Cloud Access in more than one cloud provider: %s. "+
+					"Import it with an ID in the format provider_short_name:account_id instead.", id, quoteList(shortNames)))
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+}
+
+// setCloudAccessAccountIdentity sets the resource identity from the
+// provider_short_name and account_id in data. identity is nil if Terraform
+// does not support resource identity.
+func setCloudAccessAccountIdentity(ctx context.Context, identity *tfsdk.ResourceIdentity, data CloudAccessAccountResourceModel) diag.Diagnostics {
+	if identity == nil {
+		return nil
+	}
+
+	return identity.Set(ctx, CloudAccessAccountIdentityModel{
+		ProviderShortName: data.ProviderShortName,
+		AccountID:         data.AccountID,
+	})
+}
+
+// readCloudAccessAccount looks up a single cloud access account. It returns
+// nil if the account is not enabled for Cloud Access.
+func readCloudAccessAccount(ctx context.Context, client *apiClient, shortName string, accountID string) (*CloudAccessAccountModel, diag.Diagnostics) {
+	var d diag.Diagnostics
+
+	caps, lecap, err := client.CloudAccess.get(ctx)
+	if err != nil {
+		addAPIError(&d, "Failed to list enabled cloud access providers", lecap, err)
+		return nil, d
+	}
+
+	return flattenCloudAccessAccount(ctx, caps, shortName, accountID)
+}
+
+// cloudAccessAccountEnabled reports whether an account is enabled for Cloud
+// Access. It returns false if the enabled accounts could not be listed.
+func cloudAccessAccountEnabled(ctx context.Context, client *apiClient, shortName string, accountID string) bool {
+	caps, _, err := client.CloudAccess.get(ctx)
+	if err != nil {
+		return false
+	}
+
+	return findProviderAccount(caps, shortName, accountID) != nil
+}
+
+// goldImagesRequested reports whether access to all of images has already
+// been requested for an account.
+func goldImagesRequested(ctx context.Context, client *apiClient, shortName string, accountID string, images []string) bool {
+	caa, diag := readCloudAccessAccount(ctx, client, shortName, accountID)
+	if diag.HasError() || caa == nil {
+		return false
+	}
+
+	var requested []string
+	if diag := caa.GoldImages.ElementsAs(ctx, &requested, false); diag.HasError() {
+		return false
+	}
+
+	for _, image := range images {
+		if !slices.Contains(requested, image) {
+			return false
+		}
+	}
+
+	return true
 }
 
 type CloudAccessAccountModel struct {
@@ -442,7 +976,8 @@ func flattenCloudAccessAccount(ctx context.Context, caps *gorhsm.ListEnabledClou
 		return nil, d
 	}
 
-	caa := new(CloudAccessAccountModel)
+	// caa stays nil unless a matching account is found
+	var caa *CloudAccessAccountModel
 
 	for _, x := range caps.GetBody() {
 		if x.GetShortName() == shortName {
@@ -451,14 +986,10 @@ func flattenCloudAccessAccount(ctx context.Context, caps *gorhsm.ListEnabledClou
 					goldImageStatus := []types.Object{}
 					goldImages := []attr.Value{}
 					for _, z := range y.GetGoldImageStatus() {
-						// goldImage := make(map[string]attr.Value)
-						// goldImage["description"] = types.StringValue(z.GetDescription())
-						// goldImage["name"] = types.StringValue(z.GetName())
-						// goldImage["status"] = types.StringValue(z.GetStatus())
 						goldImage := GoldImageStatusModel{
 							Description: types.StringValue(z.GetDescription()),
-							Name:        types.StringValue(z.GetDescription()),
-							Status:      types.StringValue(*z.Status),
+							Name:        types.StringValue(z.GetName()),
+							Status:      types.StringValue(z.GetStatus()),
 						}
 						goldImageObject, diag := types.ObjectValueFrom(ctx, goldImage.AttributeTypes(), goldImage)
 						if diag.HasError() {
@@ -502,6 +1033,23 @@ func flattenCloudAccessAccount(ctx context.Context, caps *gorhsm.ListEnabledClou
 	return caa, d
 }
 
+// findAccountProviders returns the short names of the cloud providers that
+// have accountID enabled for Cloud Access.
+func findAccountProviders(caps *gorhsm.ListEnabledCloudAccessProviders200Response, accountID string) []string {
+	var shortNames []string
+	for _, x := range caps.GetBody() {
+		if findProviderAccount(caps, x.GetShortName(), accountID) != nil {
+			shortNames = append(shortNames, x.GetShortName())
+		}
+	}
+
+	return shortNames
+}
+
+// resourceCloudAccessAccountSplitID splits an ID in the format
+// provider_short_name:account_id. The cloud provider is not checked against
+// the enabled providers, so an account whose provider is no longer enabled is
+// removed from state when it is refreshed instead of failing.
 func resourceCloudAccessAccountSplitID(id string) (shortName string, accountID string, err error) {
 	splitID := strings.SplitN(id, ":", 2)
 
@@ -509,23 +1057,62 @@ func resourceCloudAccessAccountSplitID(id string) (shortName string, accountID s
 		return "", "", fmt.Errorf("the Cloud Access Account ID %s could not be split correctly", id)
 	}
 
-	name := splitID[0]
-	acctID := splitID[1]
+	return splitID[0], splitID[1], nil
+}
 
-	validProvider := false
-	for _, x := range cloudAccessAccountProviders {
-		if name == x {
-			validProvider = true
-		}
+// nicknameValue returns the nickname returned by the API as a value. The API
+// returns an empty nickname for accounts without one, which is kept null when
+// prior is null.
+func nicknameValue(nickname string, prior types.String) types.String {
+	if nickname == "" && prior.IsNull() {
+		return types.StringNull()
 	}
 
-	if !validProvider {
-		return "", "", fmt.Errorf("invalid Cloud Access Account provider %s specified in ID %s", name, id)
+	return types.StringValue(nickname)
+}
+
+// checkProviderShortName adds an error to diags if planned is not a cloud
+// provider enabled for the organization. The check is only made when the
+// provider is first set, so resources already in state keep working if the
+// enabled providers cannot be listed.
+func checkProviderShortName(ctx context.Context, client *apiClient, planned types.String, prior types.String, diags *diag.Diagnostics) {
+	if planned.IsUnknown() || planned.IsNull() || planned.Equal(prior) {
+		return
 	}
 
-	return name, acctID, nil
+	shortNames := client.CloudAccess.shortNames(ctx)
+	if !slices.Contains(shortNames, planned.ValueString()) {
+		diags.AddAttributeError(
+			path.Root("provider_short_name"),
+			"Invalid provider_short_name",
+			fmt.Sprintf("The cloud provider %q is not enabled for Cloud Access in your organization. "+
+				"The enabled cloud providers are: %s.", planned.ValueString(), quoteList(shortNames)),
+		)
+	}
 }
 
+// quoteList formats a list of names, such as cloud provider short names, for
+// use in error messages.
+func quoteList(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+
+	quoted := make([]string, len(names))
+	for i, x := range names {
+		quoted[i] = fmt.Sprintf("%q", x)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+const (
+	goldImagesRemovalWarn  = "warn"
+	goldImagesRemovalError = "error"
+)
+
+// cloudAccessAccountProviders are the cloud providers assumed to be enabled
+// when the enabled providers cannot be listed from the API.
 var cloudAccessAccountProviders = []string{
 	"AWS",
 	"GCE",
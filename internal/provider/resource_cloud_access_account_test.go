@@ -1,10 +1,22 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/umich-vci/gorhsm"
 )
 
 func TestAccResourceCloudAccessAccountAzure(t *testing.T) {
@@ -124,3 +136,662 @@ resource "rhsm_cloud_access_account" "gcp_test_account" {
 	nickname            = "Terraform Acceptance Test GCP Group"
   }
 `
+
+func TestCloudAccessAccountModifyPlanGoldImages(t *testing.T) {
+	cases := map[string]struct {
+		prior    []string
+		planned  []string
+		unset    bool
+		removal  string
+		want     []string
+		warnings int
+		errors   int
+	}{
+		"unchanged": {
+			prior:   []string{"RHEL"},
+			planned: []string{"RHEL"},
+			removal: goldImagesRemovalWarn,
+			want:    []string{"RHEL"},
+		},
+		"added": {
+			prior:   []string{"RHEL"},
+			planned: []string{"RHEL", "RHEL-HA"},
+			removal: goldImagesRemovalWarn,
+			want:    []string{"RHEL", "RHEL-HA"},
+		},
+		"removed": {
+			prior:    []string{"RHEL", "RHEL-HA"},
+			planned:  []string{"RHEL"},
+			removal:  goldImagesRemovalWarn,
+			want:     []string{"RHEL", "RHEL-HA"},
+			warnings: 1,
+		},
+		"removed and added": {
+			prior:    []string{"RHEL"},
+			planned:  []string{"RHEL-HA"},
+			removal:  goldImagesRemovalWarn,
+			want:     []string{"RHEL-HA"},
+			warnings: 1,
+		},
+		"removed with error": {
+			prior:   []string{"RHEL", "RHEL-HA"},
+			planned: []string{"RHEL"},
+			removal: goldImagesRemovalError,
+			want:    []string{"RHEL"},
+			errors:  1,
+		},
+		// the images are not managed by the resource when unset, so the
+		// default empty set does not remove them
+		"unset": {
+			prior:   []string{"RHEL", "RHEL-HA"},
+			planned: []string{},
+			unset:   true,
+			removal: goldImagesRemovalError,
+			want:    []string{"RHEL", "RHEL-HA"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			r := &CloudAccessAccountResource{}
+
+			plan := testCloudAccessAccountPlan(t, r, tc.planned, tc.removal)
+			state := testCloudAccessAccountPlan(t, r, tc.prior, tc.removal)
+
+			req := fwresource.ModifyPlanRequest{
+				Config: testCloudAccessAccountConfig(t, plan, tc.unset),
+				Plan:   plan,
+				State:  tfsdk.State{Schema: state.Schema, Raw: state.Raw},
+			}
+			resp := &fwresource.ModifyPlanResponse{Plan: plan}
+
+			r.ModifyPlan(ctx, req, resp)
+
+			if got := resp.Diagnostics.WarningsCount(); got != tc.warnings {
+				t.Errorf("expected %d warnings, got %d: %v", tc.warnings, got, resp.Diagnostics)
+			}
+			if got := resp.Diagnostics.ErrorsCount(); got != tc.errors {
+				t.Errorf("expected %d errors, got %d: %v", tc.errors, got, resp.Diagnostics)
+			}
+
+			var goldImages types.Set
+			resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("gold_images"), &goldImages)...)
+			var got []string
+			resp.Diagnostics.Append(goldImages.ElementsAs(ctx, &got, false)...)
+			slices.Sort(got)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("expected planned gold images %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+// testCloudAccessAccountPlan returns a plan for an account with goldImages.
+func testCloudAccessAccountPlan(t *testing.T, r *CloudAccessAccountResource, goldImages []string, removal string) tfsdk.Plan {
+	t.Helper()
+
+	goldImagesSet, diags := types.SetValueFrom(context.Background(), types.StringType, goldImages)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	return testPlan(t, r, &CloudAccessAccountResourceModel{
+		ID:                 types.StringValue("AWS:012345678912"),
+		AccountID:          types.StringValue("012345678912"),
+		ProviderShortName:  types.StringValue("AWS"),
+		GoldImages:         goldImagesSet,
+		GoldImagesRemoval:  types.StringValue(removal),
+		AdoptExisting:      types.BoolValue(false),
+		DeletionProtection: types.BoolValue(false),
+		RetainOnDestroy:    types.BoolValue(false),
+		WaitForGoldImages:  types.BoolValue(false),
+		Nickname:           types.StringNull(),
+		DateAdded:          types.StringUnknown(),
+		GoldImageStatus:    types.SetUnknown(types.ObjectType{AttrTypes: GoldImageStatusModel{}.AttributeTypes()}),
+		SourceID:           types.StringUnknown(),
+		Verified:           types.BoolUnknown(),
+	})
+}
+
+// testCloudAccessAccountConfig returns the configuration that plan was made
+// from, with gold_images left unset if unsetGoldImages is true.
+func testCloudAccessAccountConfig(t *testing.T, plan tfsdk.Plan, unsetGoldImages bool) tfsdk.Config {
+	t.Helper()
+
+	config := tfsdk.Plan{Schema: plan.Schema, Raw: plan.Raw.Copy()}
+	if unsetGoldImages {
+		if diags := config.SetAttribute(context.Background(), path.Root("gold_images"), types.SetNull(types.StringType)); diags.HasError() {
+			t.Fatal(diags)
+		}
+	}
+
+	return tfsdk.Config{Schema: config.Schema, Raw: config.Raw}
+}
+
+// TestCloudAccessAccountGoldImageResourcePlan checks that an account with
+// gold_images unset plans cleanly when its images are managed with
+// rhsm_cloud_access_gold_image.
+func TestCloudAccessAccountGoldImageResourcePlan(t *testing.T) {
+	api := &testCloudAccessAPI{shortName: "AWS"}
+
+	ctx := context.Background()
+	client := testAPIClient(t, api.handler(t))
+	r := &CloudAccessAccountResource{client: client}
+	g := &CloudAccessGoldImageResource{client: client}
+
+	plan := testCloudAccessAccountPlan(t, r, []string{}, goldImagesRemovalError)
+	createResp := &fwresource.CreateResponse{State: tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Raw.Type(), nil)}}
+	r.Create(ctx, fwresource.CreateRequest{Plan: plan}, createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatal(createResp.Diagnostics)
+	}
+
+	imagePlan := testCloudAccessGoldImagePlan(t, g, "RHEL")
+	imageResp := &fwresource.CreateResponse{State: tfsdk.State{Schema: imagePlan.Schema, Raw: tftypes.NewValue(imagePlan.Raw.Type(), nil)}}
+	g.Create(ctx, fwresource.CreateRequest{Plan: imagePlan}, imageResp)
+	if imageResp.Diagnostics.HasError() {
+		t.Fatal(imageResp.Diagnostics)
+	}
+
+	readResp := &fwresource.ReadResponse{State: createResp.State}
+	r.Read(ctx, fwresource.ReadRequest{State: createResp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatal(readResp.Diagnostics)
+	}
+
+	var goldImages []string
+	if diags := readResp.State.GetAttribute(ctx, path.Root("gold_images"), &goldImages); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if !slices.Equal(goldImages, []string{"RHEL"}) {
+		t.Fatalf("expected the refreshed gold images to be [RHEL], got %v", goldImages)
+	}
+
+	// gold_images is unset in the configuration, so it is planned with its
+	// default
+	planned := tfsdk.Plan{Schema: readResp.State.Schema, Raw: readResp.State.Raw.Copy()}
+	if diags := planned.SetAttribute(ctx, path.Root("gold_images"), types.SetValueMust(types.StringType, []attr.Value{})); diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	resp := &fwresource.ModifyPlanResponse{Plan: planned}
+	r.ModifyPlan(ctx, fwresource.ModifyPlanRequest{
+		Config: testCloudAccessAccountConfig(t, planned, true),
+		Plan:   planned,
+		State:  readResp.State,
+	}, resp)
+
+	if len(resp.Diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %v", resp.Diagnostics)
+	}
+	if !resp.Plan.Raw.Equal(readResp.State.Raw) {
+		t.Errorf("expected no changes to be planned, got %s", resp.Plan.Raw)
+	}
+}
+
+func TestCloudAccessAccountModifyPlanDestroy(t *testing.T) {
+	cases := map[string]struct {
+		deletionProtection bool
+		retainOnDestroy    bool
+		replace            bool
+		warnings           int
+		errors             int
+	}{
+		"destroy": {},
+		"protected": {
+			deletionProtection: true,
+			errors:             1,
+		},
+		"protected replace": {
+			deletionProtection: true,
+			replace:            true,
+			errors:             1,
+		},
+		"retained": {
+			retainOnDestroy: true,
+			warnings:        1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			r := &CloudAccessAccountResource{}
+
+			state := testCloudAccessAccountPlan(t, r, []string{"RHEL"}, goldImagesRemovalWarn)
+			diags := state.SetAttribute(ctx, path.Root("deletion_protection"), tc.deletionProtection)
+			diags.Append(state.SetAttribute(ctx, path.Root("retain_on_destroy"), tc.retainOnDestroy)...)
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+
+			plan := tfsdk.Plan{Schema: state.Schema, Raw: tftypes.NewValue(state.Raw.Type(), nil)}
+			resp := &fwresource.ModifyPlanResponse{Plan: plan}
+			if tc.replace {
+				plan = state
+				resp.Plan = plan
+				resp.RequiresReplace = path.Paths{path.Root("account_id")}
+			}
+
+			r.ModifyPlan(ctx, fwresource.ModifyPlanRequest{
+				Plan:  plan,
+				State: tfsdk.State{Schema: state.Schema, Raw: state.Raw},
+			}, resp)
+
+			if got := resp.Diagnostics.WarningsCount(); got != tc.warnings {
+				t.Errorf("expected %d warnings, got %d: %v", tc.warnings, got, resp.Diagnostics)
+			}
+			if got := resp.Diagnostics.ErrorsCount(); got != tc.errors {
+				t.Errorf("expected %d errors, got %d: %v", tc.errors, got, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestCloudAccessAccountDeleteRetained(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	ctx := context.Background()
+	r := &CloudAccessAccountResource{client: testAPIClient(t, mux)}
+
+	state := testCloudAccessAccountPlan(t, r, []string{"RHEL"}, goldImagesRemovalWarn)
+	if diags := state.SetAttribute(ctx, path.Root("retain_on_destroy"), true); diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	resp := &fwresource.DeleteResponse{State: tfsdk.State{Schema: state.Schema, Raw: state.Raw}}
+	r.Delete(ctx, fwresource.DeleteRequest{State: tfsdk.State{Schema: state.Schema, Raw: state.Raw}}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+
+	if !resp.State.Raw.IsNull() {
+		t.Error("expected the account to be removed from state")
+	}
+}
+
+// TestCloudAccessAccountDeleteAlreadyRemoved checks that a 404, such as
+// from a retried request whose first attempt removed the account, is not an
+// error.
+func TestCloudAccessAccountDeleteAlreadyRemoved(t *testing.T) {
+	api := &testCloudAccessAPI{shortName: "AWS"}
+
+	ctx := context.Background()
+	r := &CloudAccessAccountResource{client: testAPIClient(t, api.handler(t))}
+
+	state := testCloudAccessAccountPlan(t, r, []string{"RHEL"}, goldImagesRemovalWarn)
+	resp := &fwresource.DeleteResponse{State: tfsdk.State{Schema: state.Schema, Raw: state.Raw}}
+	r.Delete(ctx, fwresource.DeleteRequest{State: tfsdk.State{Schema: state.Schema, Raw: state.Raw}}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+
+	if !resp.State.Raw.IsNull() {
+		t.Error("expected the account to be removed from state")
+	}
+}
+
+func TestCloudAccessAccountCreateAdoptsExisting(t *testing.T) {
+	api := &testCloudAccessAPI{
+		shortName: "AWS",
+		accounts: []gorhsm.EnabledProviderAccount{{
+			Id:       "012345678912",
+			Nickname: "Added in the console",
+			GoldImageStatus: []gorhsm.GoldImageStatus{
+				{Name: gorhsm.PtrString("RHEL"), Status: gorhsm.PtrString("Granted")},
+			},
+		}},
+	}
+
+	ctx := context.Background()
+	r := &CloudAccessAccountResource{client: testAPIClient(t, api.handler(t))}
+
+	plan := testCloudAccessAccountPlan(t, r, []string{"RHEL", "RHEL-HA"}, goldImagesRemovalWarn)
+	diags := plan.SetAttribute(ctx, path.Root("adopt_existing"), true)
+	diags.Append(plan.SetAttribute(ctx, path.Root("nickname"), "Managed by Terraform")...)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	identitySchemaResp := &fwresource.IdentitySchemaResponse{}
+	r.IdentitySchema(ctx, fwresource.IdentitySchemaRequest{}, identitySchemaResp)
+	identity := &tfsdk.ResourceIdentity{
+		Schema: identitySchemaResp.IdentitySchema,
+		Raw:    tftypes.NewValue(identitySchemaResp.IdentitySchema.Type().TerraformType(ctx), nil),
+	}
+
+	resp := &fwresource.CreateResponse{
+		State:    tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Raw.Type(), nil)},
+		Identity: identity,
+	}
+	r.Create(ctx, fwresource.CreateRequest{Plan: plan}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+
+	if len(api.added) != 0 {
+		t.Errorf("expected an existing account not to be added again, got %v", api.added)
+	}
+	if !slices.Equal(api.requested, []string{"RHEL-HA"}) {
+		t.Errorf("expected only RHEL-HA to be requested, got %v", api.requested)
+	}
+
+	var data CloudAccessAccountResourceModel
+	resp.Diagnostics.Append(resp.State.Get(ctx, &data)...)
+	if data.Nickname.ValueString() != "Managed by Terraform" {
+		t.Errorf("expected the nickname to be updated, got %s", data.Nickname)
+	}
+	if data.ID.ValueString() != "AWS:012345678912" {
+		t.Errorf("unexpected ID %s", data.ID)
+	}
+	var identityData CloudAccessAccountIdentityModel
+	resp.Diagnostics.Append(resp.Identity.Get(ctx, &identityData)...)
+	if identityData.ProviderShortName.ValueString() != "AWS" || identityData.AccountID.ValueString() != "012345678912" {
+		t.Errorf("unexpected identity %s:%s", identityData.ProviderShortName, identityData.AccountID)
+	}
+}
+
+func TestCloudAccessAccountImportState(t *testing.T) {
+	api := &testCloudAccessAPI{
+		shortName: "AWS",
+		accounts:  []gorhsm.EnabledProviderAccount{{Id: "012345678912"}, {Id: "shared"}},
+		others: []gorhsm.EnabledCloudAccessProvider{{
+			ShortName: gorhsm.PtrString("GCE"),
+			Accounts:  []gorhsm.EnabledProviderAccount{{Id: "shared"}},
+		}},
+	}
+
+	ctx := context.Background()
+	r := &CloudAccessAccountResource{client: testAPIClient(t, api.handler(t))}
+
+	schemaResp := &fwresource.SchemaResponse{}
+	r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+	identitySchemaResp := &fwresource.IdentitySchemaResponse{}
+	r.IdentitySchema(ctx, fwresource.IdentitySchemaRequest{}, identitySchemaResp)
+
+	cases := []struct {
+		name      string
+		id        string
+		identity  *CloudAccessAccountIdentityModel
+		expectID  string
+		expectErr bool
+	}{
+		{name: "id", id: "MSAZ:00000000-0000-0000-0000-000000000000", expectID: "MSAZ:00000000-0000-0000-0000-000000000000"},
+		{name: "account id", id: "012345678912", expectID: "AWS:012345678912"},
+		{name: "ambiguous account id", id: "shared", expectErr: true},
+		{name: "missing account id", id: "missing", expectErr: true},
+		{
+			name:     "identity",
+			identity: &CloudAccessAccountIdentityModel{ProviderShortName: types.StringValue("GCE"), AccountID: types.StringValue("shared")},
+			expectID: "GCE:shared",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := fwresource.ImportStateRequest{ID: c.id}
+			if c.identity != nil {
+				req.Identity = &tfsdk.ResourceIdentity{Schema: identitySchemaResp.IdentitySchema}
+				if diags := req.Identity.Set(ctx, c.identity); diags.HasError() {
+					t.Fatal(diags)
+				}
+			}
+
+			state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+			resp := &fwresource.ImportStateResponse{State: state}
+			r.ImportState(ctx, req, resp)
+
+			if c.expectErr {
+				if !resp.Diagnostics.HasError() {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatal(resp.Diagnostics)
+			}
+
+			var id types.String
+			resp.Diagnostics.Append(resp.State.GetAttribute(ctx, path.Root("id"), &id)...)
+			if id.ValueString() != c.expectID {
+				t.Errorf("expected ID %s, got %s", c.expectID, id)
+			}
+		})
+	}
+}
+
+func TestCloudAccessAccountCreateAdoptsMissing(t *testing.T) {
+	api := &testCloudAccessAPI{shortName: "AWS"}
+
+	ctx := context.Background()
+	r := &CloudAccessAccountResource{client: testAPIClient(t, api.handler(t))}
+
+	plan := testCloudAccessAccountPlan(t, r, []string{"RHEL"}, goldImagesRemovalWarn)
+	if diags := plan.SetAttribute(ctx, path.Root("adopt_existing"), true); diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	resp := &fwresource.CreateResponse{State: tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Raw.Type(), nil)}}
+	r.Create(ctx, fwresource.CreateRequest{Plan: plan}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+
+	if !slices.Equal(api.added, []string{"012345678912"}) {
+		t.Errorf("expected the missing account to be added, got %v", api.added)
+	}
+	if !slices.Equal(api.requested, []string{"RHEL"}) {
+		t.Errorf("expected RHEL to be requested, got %v", api.requested)
+	}
+}
+
+func TestCloudAccessAccountReadRemoved(t *testing.T) {
+	api := &testCloudAccessAPI{shortName: "AWS"}
+
+	ctx := context.Background()
+	r := &CloudAccessAccountResource{client: testAPIClient(t, api.handler(t))}
+
+	state := testCloudAccessAccountPlan(t, r, []string{"RHEL"}, goldImagesRemovalWarn)
+	resp := &fwresource.ReadResponse{State: tfsdk.State{Schema: state.Schema, Raw: state.Raw}}
+	r.Read(ctx, fwresource.ReadRequest{State: tfsdk.State{Schema: state.Schema, Raw: state.Raw}}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+
+	if !resp.State.Raw.IsNull() {
+		t.Error("expected an account removed outside of Terraform to be removed from state")
+	}
+}
+
+func TestCloudAccessAccountReadProviderRemoved(t *testing.T) {
+	// only MSAZ is enabled, so the AWS account is no longer listed
+	api := &testCloudAccessAPI{shortName: "MSAZ"}
+
+	ctx := context.Background()
+	r := &CloudAccessAccountResource{client: testAPIClient(t, api.handler(t))}
+
+	state := testCloudAccessAccountPlan(t, r, []string{"RHEL"}, goldImagesRemovalWarn)
+	resp := &fwresource.ReadResponse{State: tfsdk.State{Schema: state.Schema, Raw: state.Raw}}
+	r.Read(ctx, fwresource.ReadRequest{State: tfsdk.State{Schema: state.Schema, Raw: state.Raw}}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+
+	if !resp.State.Raw.IsNull() {
+		t.Error("expected an account of a cloud provider that is no longer enabled to be removed from state")
+	}
+}
+
+func TestCloudAccessAccountCreateFailed(t *testing.T) {
+	cases := map[string]struct {
+		accounts  []gorhsm.EnabledProviderAccount
+		addStatus int
+		errors    []string
+	}{
+		"missing": {
+			addStatus: http.StatusForbidden,
+			errors:    []string{"Failed to create Cloud Access Account: insufficient Cloud Access entitlement or permissions"},
+		},
+		// the outcome of a 502 is unknown, but the account was not added
+		"bad gateway": {
+			addStatus: http.StatusBadGateway,
+			errors:    []string{"Failed to create Cloud Access Account: RHSM API error"},
+		},
+		"exists": {
+			accounts:  []gorhsm.EnabledProviderAccount{{Id: "012345678912"}},
+			addStatus: http.StatusForbidden,
+			errors: []string{
+				"Failed to create Cloud Access Account: insufficient Cloud Access entitlement or permissions",
+				"Cloud Access Account already exists",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			api := &testCloudAccessAPI{shortName: "AWS", accounts: tc.accounts, addStatus: tc.addStatus}
+
+			ctx := context.Background()
+			r := &CloudAccessAccountResource{client: testAPIClient(t, api.handler(t))}
+
+			plan := testCloudAccessAccountPlan(t, r, []string{}, goldImagesRemovalWarn)
+			resp := &fwresource.CreateResponse{State: tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Raw.Type(), nil)}}
+			r.Create(ctx, fwresource.CreateRequest{Plan: plan}, resp)
+
+			var summaries []string
+			for _, d := range resp.Diagnostics.Errors() {
+				summaries = append(summaries, d.Summary())
+			}
+			if !slices.Equal(summaries, tc.errors) {
+				t.Errorf("expected errors %q, got %q", tc.errors, summaries)
+			}
+		})
+	}
+}
+
+// testCloudAccessAPI is a stand-in for the RHSM Cloud Access API with the
+// accounts of a single cloud provider.
+type testCloudAccessAPI struct {
+	mu        sync.Mutex
+	shortName string
+	accounts  []gorhsm.EnabledProviderAccount
+	// others are listed after shortName and are never changed
+	others []gorhsm.EnabledCloudAccessProvider
+	// addStatus is returned when adding accounts without adding them, if set
+	addStatus int
+	// beforeList is called with the lock held before the accounts are
+	// listed, if set, so tests can change them between polls
+	beforeList    func()
+	added         []string
+	requested     []string
+	verifications []gorhsm.VerifyProviderAccountRequest
+}
+
+func (a *testCloudAccessAPI) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /cloud_access_providers/enabled", func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		if a.beforeList != nil {
+			a.beforeList()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(gorhsm.ListEnabledCloudAccessProviders200Response{
+			Body: append([]gorhsm.EnabledCloudAccessProvider{{
+				ShortName: gorhsm.PtrString(a.shortName),
+				Accounts:  a.accounts,
+			}}, a.others...),
+		}); err != nil {
+			t.Error(err)
+		}
+	})
+	mux.HandleFunc("POST /cloud_access_providers/{shortName}/accounts", func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		if a.addStatus != 0 {
+			w.WriteHeader(a.addStatus)
+			return
+		}
+
+		var accounts []gorhsm.AddProviderAccount
+		if err := json.NewDecoder(r.Body).Decode(&accounts); err != nil {
+			t.Error(err)
+		}
+		for _, account := range accounts {
+			a.added = append(a.added, account.GetId())
+			a.accounts = append(a.accounts, gorhsm.EnabledProviderAccount{Id: account.GetId(), Nickname: account.GetNickname()})
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("PUT /cloud_access_providers/{shortName}/accounts/{accountID}", func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		var update gorhsm.UpdateProviderAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			t.Error(err)
+		}
+		for i := range a.accounts {
+			if a.accounts[i].Id == r.PathValue("accountID") {
+				a.accounts[i].Nickname = update.Nickname
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("PUT /cloud_access_providers/{shortName}/accounts/{accountID}/verification", func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		var verification gorhsm.VerifyProviderAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&verification); err != nil {
+			t.Error(err)
+		}
+		a.verifications = append(a.verifications, verification)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /cloud_access_providers/{shortName}/goldimage", func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		var gi gorhsm.EnableGoldImagesRequest
+		if err := json.NewDecoder(r.Body).Decode(&gi); err != nil {
+			t.Error(err)
+		}
+		a.requested = append(a.requested, gi.Images...)
+		for i := range a.accounts {
+			if !slices.Contains(gi.Accounts, a.accounts[i].Id) {
+				continue
+			}
+			for _, image := range gi.Images {
+				a.accounts[i].GoldImageStatus = append(a.accounts[i].GoldImageStatus,
+					gorhsm.GoldImageStatus{Name: gorhsm.PtrString(image), Status: gorhsm.PtrString("Requested")})
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("DELETE /cloud_access_providers/{shortName}/accounts", func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		var remove gorhsm.RemoveProviderAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&remove); err != nil {
+			t.Error(err)
+		}
+		if !slices.ContainsFunc(a.accounts, func(x gorhsm.EnabledProviderAccount) bool { return x.Id == remove.Id }) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		a.accounts = slices.DeleteFunc(a.accounts, func(x gorhsm.EnabledProviderAccount) bool { return x.Id == remove.Id })
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return mux
+}
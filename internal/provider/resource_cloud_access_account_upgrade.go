@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// cloudAccessAccountSchemaVersion is the current version of the
+// rhsm_cloud_access_account schema. Bump it and add an upgrader to
+// UpgradeState whenever the shape of the state changes.
+const cloudAccessAccountSchemaVersion = 1
+
+// cloudAccessAccountStateV0 is the state of rhsm_cloud_access_account before
+// the schema was versioned. It was written by the SDKv2 resource in 0.6.x and
+// by the framework resource from 0.7.0, so every attribute that was added
+// since may be missing. The id is rebuilt from account_id and
+// provider_short_name.
+type cloudAccessAccountStateV0 struct {
+	AccountID          string                          `json:"account_id"`
+	ProviderShortName  string                          `json:"provider_short_name"`
+	GoldImages         []string                        `json:"gold_images"`
+	GoldImagesRemoval  *string                         `json:"gold_images_removal"`
+	AdoptExisting      *bool                           `json:"adopt_existing"`
+	DeletionProtection *bool                           `json:"deletion_protection"`
+	RetainOnDestroy    *bool                           `json:"retain_on_destroy"`
+	WaitForGoldImages  *bool                           `json:"wait_for_gold_images"`
+	Nickname           *string                         `json:"nickname"`
+	DateAdded          *string                         `json:"date_added"`
+	GoldImageStatus    []cloudAccessAccountGoldImageV0 `json:"gold_image_status"`
+	SourceID           *string                         `json:"source_id"`
+	Verified           *bool                           `json:"verified"`
+	Timeouts           map[string]*string              `json:"timeouts"`
+}
+
+type cloudAccessAccountGoldImageV0 struct {
+	Description *string `json:"description"`
+	Name        *string `json:"name"`
+	Status      *string `json:"status"`
+}
+
+func (r *CloudAccessAccountResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// the state from 0.6.x and 0.7.x is read from the raw JSON since the
+		// SDKv2 and framework resources did not write the same attributes
+		0: {
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var prior cloudAccessAccountStateV0
+				if err := json.Unmarshal(req.RawState.JSON, &prior); err != nil {
+					resp.Diagnostics.AddError("Failed to upgrade Cloud Access Account state", fmt.Sprintf("The prior state could not be decoded: %s", err))
+					return
+				}
+
+				data, diags := upgradeCloudAccessAccountStateV0(ctx, prior)
+				resp.Diagnostics.Append(diags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				resp.Diagnostics.Append(resp.State.Set(ctx, data)...)
+			},
+		},
+	}
+}
+
+// upgradeCloudAccessAccountStateV0 converts unversioned state to the current
+// schema, filling in the defaults of attributes that did not exist yet.
+func upgradeCloudAccessAccountStateV0(ctx context.Context, prior cloudAccessAccountStateV0) (*CloudAccessAccountResourceModel, diag.Diagnostics) {
+	var d diag.Diagnostics
+
+	data := &CloudAccessAccountResourceModel{
+		ID:                 types.StringValue(fmt.Sprintf("%s:%s", prior.ProviderShortName, prior.AccountID)),
+		AccountID:          types.StringValue(prior.AccountID),
+		ProviderShortName:  types.StringValue(prior.ProviderShortName),
+		GoldImagesRemoval:  types.StringValue(goldImagesRemovalWarn),
+		AdoptExisting:      types.BoolValue(false),
+		DeletionProtection: types.BoolValue(false),
+		RetainOnDestroy:    types.BoolValue(false),
+		WaitForGoldImages:  types.BoolValue(false),
+		Nickname:           types.StringPointerValue(prior.Nickname),
+		DateAdded:          types.StringPointerValue(prior.DateAdded),
+		SourceID:           types.StringPointerValue(prior.SourceID),
+		Verified:           types.BoolPointerValue(prior.Verified),
+	}
+
+	// the SDKv2 resource wrote an empty string for an unset nickname
+	if prior.Nickname != nil && *prior.Nickname == "" {
+		data.Nickname = types.StringNull()
+	}
+
+	if prior.GoldImagesRemoval != nil {
+		data.GoldImagesRemoval = types.StringValue(*prior.GoldImagesRemoval)
+	}
+	if prior.AdoptExisting != nil {
+		data.AdoptExisting = types.BoolValue(*prior.AdoptExisting)
+	}
+	if prior.DeletionProtection != nil {
+		data.DeletionProtection = types.BoolValue(*prior.DeletionProtection)
+	}
+	if prior.RetainOnDestroy != nil {
+		data.RetainOnDestroy = types.BoolValue(*prior.RetainOnDestroy)
+	}
+	if prior.WaitForGoldImages != nil {
+		data.WaitForGoldImages = types.BoolValue(*prior.WaitForGoldImages)
+	}
+
+	// an unset gold_images was null before it defaulted to an empty set
+	goldImages := prior.GoldImages
+	if goldImages == nil {
+		goldImages = []string{}
+	}
+
+	goldImagesSet, diag := types.SetValueFrom(ctx, types.StringType, goldImages)
+	d.Append(diag...)
+
+	goldImageStatus := make([]GoldImageStatusModel, len(prior.GoldImageStatus))
+	for i, x := range prior.GoldImageStatus {
+		goldImageStatus[i] = GoldImageStatusModel{
+			Description: types.StringPointerValue(x.Description),
+			Name:        types.StringPointerValue(x.Name),
+			Status:      types.StringPointerValue(x.Status),
+		}
+
+		// the name was filled with the description before 0.8.0, which
+		// cannot be mapped back to the image name, so it is left for the
+		// next refresh to fill in
+		if x.Name != nil && !slices.Contains(goldImages, *x.Name) {
+			goldImageStatus[i].Name = types.StringNull()
+		}
+	}
+
+	goldImageStatusSet, diag := types.SetValueFrom(ctx,
+		types.ObjectType{AttrTypes: GoldImageStatusModel{}.AttributeTypes()}, goldImageStatus)
+	d.Append(diag...)
+
+	timeoutsValue, diag := upgradeCloudAccessAccountTimeouts(prior.Timeouts)
+	d.Append(diag...)
+
+	if d.HasError() {
+		return nil, d
+	}
+
+	data.GoldImages = goldImagesSet
+	data.GoldImageStatus = goldImageStatusSet
+	data.Timeouts = timeoutsValue
+
+	return data, d
+}
+
+// upgradeCloudAccessAccountTimeouts converts the timeouts block, which did not
+// exist before 0.8.0.
+func upgradeCloudAccessAccountTimeouts(prior map[string]*string) (timeouts.Value, diag.Diagnostics) {
+	attrTypes := map[string]attr.Type{
+		"create": types.StringType,
+		"read":   types.StringType,
+		"update": types.StringType,
+		"delete": types.StringType,
+	}
+
+	if prior == nil {
+		return timeouts.Value{Object: types.ObjectNull(attrTypes)}, nil
+	}
+
+	attrs := make(map[string]attr.Value, len(attrTypes))
+	for name := range attrTypes {
+		attrs[name] = types.StringPointerValue(prior[name])
+	}
+
+	object, d := types.ObjectValue(attrTypes, attrs)
+
+	return timeouts.Value{Object: object}, d
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestCloudAccessAccountUpgradeState(t *testing.T) {
+	cases := map[string]struct {
+		fixture    string
+		id         string
+		nickname   types.String
+		goldImages []string
+		verified   bool
+	}{
+		"0.6.1": {
+			fixture:    "state_v0.6.1.json",
+			id:         "AWS:012345678912",
+			nickname:   types.StringNull(),
+			goldImages: []string{"rhel"},
+		},
+		"0.7.0": {
+			fixture:    "state_v0.7.0.json",
+			id:         "MSAZ:123e4567-e89b-12d3-a456-426614174000",
+			nickname:   types.StringValue("Terraform Acceptance Test Azure Account"),
+			goldImages: []string{"rhel", "rhel-ha"},
+			verified:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			r := &CloudAccessAccountResource{}
+
+			rawState, err := os.ReadFile(filepath.Join("testdata", "cloud_access_account", tc.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			schemaResp := &fwresource.SchemaResponse{}
+			r.Schema(ctx, fwresource.SchemaRequest{}, schemaResp)
+
+			upgrader, ok := r.UpgradeState(ctx)[0]
+			if !ok {
+				t.Fatal("expected an upgrader for version 0")
+			}
+
+			resp := &fwresource.UpgradeStateResponse{
+				State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)},
+			}
+			upgrader.StateUpgrader(ctx, fwresource.UpgradeStateRequest{RawState: &tfprotov6.RawState{JSON: rawState}}, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatal(resp.Diagnostics)
+			}
+
+			var data CloudAccessAccountResourceModel
+			if diags := resp.State.Get(ctx, &data); diags.HasError() {
+				t.Fatal(diags)
+			}
+
+			if data.ID.ValueString() != tc.id {
+				t.Errorf("expected ID %s, got %s", tc.id, data.ID)
+			}
+			if !data.Nickname.Equal(tc.nickname) {
+				t.Errorf("expected nickname %s, got %s", tc.nickname, data.Nickname)
+			}
+			if data.Verified.ValueBool() != tc.verified {
+				t.Errorf("expected verified %t, got %s", tc.verified, data.Verified)
+			}
+
+			var goldImages []string
+			resp.Diagnostics.Append(data.GoldImages.ElementsAs(ctx, &goldImages, false)...)
+			slices.Sort(goldImages)
+			if !slices.Equal(goldImages, tc.goldImages) {
+				t.Errorf("expected gold images %v, got %v", tc.goldImages, goldImages)
+			}
+
+			// the names were filled with the description, so they are left
+			// for the next refresh
+			var statuses []GoldImageStatusModel
+			resp.Diagnostics.Append(data.GoldImageStatus.ElementsAs(ctx, &statuses, false)...)
+			if len(statuses) != len(tc.goldImages) {
+				t.Fatalf("expected %d gold image statuses, got %d", len(tc.goldImages), len(statuses))
+			}
+			for _, x := range statuses {
+				if !x.Name.IsNull() || x.Description.IsNull() {
+					t.Errorf("unexpected gold image status %+v", x)
+				}
+			}
+
+			// attributes added since default to their configured defaults so
+			// the upgrade does not cause a diff
+			if data.AdoptExisting.ValueBool() || data.DeletionProtection.ValueBool() || data.RetainOnDestroy.ValueBool() || data.WaitForGoldImages.ValueBool() {
+				t.Errorf("expected new boolean attributes to default to false, got %+v", data)
+			}
+			if data.GoldImagesRemoval.ValueString() != goldImagesRemovalWarn {
+				t.Errorf("expected gold_images_removal to default to %s, got %s", goldImagesRemovalWarn, data.GoldImagesRemoval)
+			}
+			if !data.Timeouts.IsNull() {
+				t.Errorf("expected timeouts to be null, got %s", data.Timeouts)
+			}
+		})
+	}
+}
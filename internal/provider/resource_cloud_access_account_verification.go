@@ -0,0 +1,334 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/umich-vci/gorhsm"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &CloudAccessAccountVerificationResource{}
+var _ resource.ResourceWithValidateConfig = &CloudAccessAccountVerificationResource{}
+var _ resource.ResourceWithConfigValidators = &CloudAccessAccountVerificationResource{}
+
+func NewCloudAccessAccountVerificationResource() resource.Resource {
+	return &CloudAccessAccountVerificationResource{}
+}
+
+// CloudAccessAccountVerificationResource defines the resource implementation.
+type CloudAccessAccountVerificationResource struct {
+	client *apiClient
+}
+
+// CloudAccessAccountVerificationResourceModel describes the resource data model.
+type CloudAccessAccountVerificationResourceModel struct {
+	ID                types.String   `tfsdk:"id"`
+	AccountID         types.String   `tfsdk:"account_id"`
+	ProviderShortName types.String   `tfsdk:"provider_short_name"`
+	Identity          types.String   `tfsdk:"identity"`
+	Signature         types.String   `tfsdk:"signature"`
+	Verified          types.Bool     `tfsdk:"verified"`
+	Timeouts          timeouts.Value `tfsdk:"timeouts"`
+}
+
+// unsignedVerificationProviders are the cloud providers whose identity
+// document is a signed token that is sent without a separate signature.
+var unsignedVerificationProviders = []string{"GCE"}
+
+func (r *CloudAccessAccountVerificationResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cloud_access_account_verification"
+}
+
+func (r *CloudAccessAccountVerificationResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Resource to verify a Red Hat Cloud Access account for RHSM Auto Registration, which allows cloud instances " +
+			"in the account to register with RHSM automatically. The account is verified with the identity document of an " +
+			"instance running in it, and the resource waits until RHSM reports the account as verified. Verification cannot be " +
+			"removed through the API, so destroying this resource only removes it from state.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The ID of the cloud account in the format `provider_short_name:account_id`.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"account_id": schema.StringAttribute{
+				Description: "The ID of the cloud account to verify. The account must already be enabled for Red Hat Cloud Access. For AWS this must be a 12 digit account ID, for MSAZ a subscription ID in GUID format, and for GCE the email address of a Google Group.",
+				Required:    true,
+				Validators:  []validator.String{stringvalidator.NoneOf("")},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"provider_short_name": schema.StringAttribute{
+				Description: "The short name of the cloud provider that the `account_id` is in, such as \"AWS\", \"GCE\", or \"MSAZ\".",
+				Required:    true,
+				Validators:  []validator.String{stringvalidator.NoneOf("")},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"identity": schema.StringAttribute{
+				MarkdownDescription: "The identity document of an instance in the account from the cloud provider's metadata service. " +
+					"For AWS this is the base64-encoded EC2 instance identity document and for Azure it is the base64-encoded attested data document. " +
+					"For GCE this is the instance identity token as it is, generated with an `audience` of `https://subscription.rhsm.redhat.com:443/subscription` and a `format` of `full`.",
+				Required:  true,
+				Sensitive: true,
+				Validators: []validator.String{
+					stringvalidator.NoneOf(""),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"signature": schema.StringAttribute{
+				MarkdownDescription: "The base64-encoded signature of the `identity` document from the cloud provider's metadata service. " +
+					"This is required for every cloud provider except GCE, where it must be omitted.",
+				Optional:  true,
+				Sensitive: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"verified": schema.BoolAttribute{
+				Description: "Is the cloud provider account verified for RHSM Auto Registration?",
+				Computed:    true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+			}),
+		},
+	}
+}
+
+func (r *CloudAccessAccountVerificationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data CloudAccessAccountVerificationResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() || data.ProviderShortName.IsUnknown() || data.Signature.IsUnknown() {
+		return
+	}
+
+	unsigned := slices.Contains(unsignedVerificationProviders, data.ProviderShortName.ValueString())
+
+	switch {
+	case unsigned && !data.Signature.IsNull():
+		resp.Diagnostics.AddAttributeError(
+			path.Root("signature"),
+			"Unexpected signature",
+			fmt.Sprintf("The identity token for %s is signed, so signature must not be set.", data.ProviderShortName.ValueString()),
+		)
+	case !unsigned && data.Signature.IsNull():
+		resp.Diagnostics.AddAttributeError(
+			path.Root("signature"),
+			"Missing signature",
+			fmt.Sprintf("The signature of the identity document must be set to verify a %s account.", data.ProviderShortName.ValueString()),
+		)
+	}
+}
+
+func (r *CloudAccessAccountVerificationResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		accountIDValidator{},
+	}
+}
+
+func (r *CloudAccessAccountVerificationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*apiClient)
+	if !ok {
+		resp.Diagnostics.AddError("Failed to configure Cloud Access Account Verification resource", "Invalid provider data")
+		return
+	}
+
+	r.client = client
+}
+
+func (r *CloudAccessAccountVerificationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data CloudAccessAccountVerificationResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := data.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	shortName := data.ProviderShortName.ValueString()
+	accountID := data.AccountID.ValueString()
+
+	ctx = tflog.SetField(ctx, "provider_short_name", shortName)
+	ctx = tflog.SetField(ctx, "account_id", accountID)
+
+	data.ID = types.StringValue(fmt.Sprintf("%s:%s", shortName, accountID))
+
+	verification := gorhsm.VerifyProviderAccountRequest{
+		Identity:  data.Identity.ValueString(),
+		Signature: data.Signature.ValueString(),
+	}
+
+	// verifying an account that is already verified makes no changes, so
+	// this is safe to retry
+	vpa, err := r.client.Client.CloudaccessAPI.VerifyProviderAccount(ctx, shortName, accountID).Account(verification).Execute()
+	r.client.CloudAccess.invalidate()
+	if vpa != nil {
+		vpa.Body.Close()
+	}
+	if err != nil {
+		if addTimeoutError(ctx, &resp.Diagnostics, "verify the cloud access account") {
+			return
+		}
+		addAPIError(&resp.Diagnostics, "Failed to verify Cloud Access Account", vpa, err)
+		return
+	}
+
+	resp.Diagnostics.Append(waitFor(ctx, "wait for the cloud access account to be verified", func() (bool, diag.Diagnostics) {
+		// always ask the API for the current status
+		r.client.CloudAccess.invalidate()
+
+		verified, d := r.readVerified(ctx, shortName, accountID)
+		if !d.HasError() && verified == nil {
+			d.AddError("Failed to find verified Cloud Access Account", "No matching account was found")
+		}
+		if d.HasError() {
+			return false, d
+		}
+
+		return *verified, d
+	})...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Verified = types.BoolValue(true)
+
+	tflog.Trace(ctx, "created a resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CloudAccessAccountVerificationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data CloudAccessAccountVerificationResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	shortName := data.ProviderShortName.ValueString()
+	accountID := data.AccountID.ValueString()
+
+	ctx = tflog.SetField(ctx, "provider_short_name", shortName)
+	ctx = tflog.SetField(ctx, "account_id", accountID)
+
+	verified, diags := r.readVerified(ctx, shortName, accountID)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the account was removed or is no longer verified, so it needs to be
+	// verified again
+	if verified == nil || !*verified {
+		tflog.Debug(ctx, "cloud access account is no longer verified")
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	data.Verified = types.BoolValue(true)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CloudAccessAccountVerificationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state CloudAccessAccountVerificationResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// every other attribute requires replacement, so only the timeouts can
+	// change here
+	data.Verified = state.Verified
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CloudAccessAccountVerificationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// the API cannot remove the verification of an account
+	resp.State.RemoveResource(ctx)
+}
+
+// readVerified returns whether an account is verified, or nil if the account
+// is not enabled for Cloud Access.
+func (r *CloudAccessAccountVerificationResource) readVerified(ctx context.Context, shortName string, accountID string) (*bool, diag.Diagnostics) {
+	var d diag.Diagnostics
+
+	caps, lecap, err := r.client.CloudAccess.get(ctx)
+	if err != nil {
+		if addTimeoutError(ctx, &d, "list enabled cloud access providers") {
+			return nil, d
+		}
+		addAPIError(&d, "Failed to list enabled cloud access providers", lecap, err)
+		return nil, d
+	}
+
+	account := findProviderAccount(caps, shortName, accountID)
+	if account == nil {
+		return nil, d
+	}
+
+	verified := account.GetVerified()
+	return &verified, d
+}
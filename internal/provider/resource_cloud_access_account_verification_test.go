@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/umich-vci/gorhsm"
+)
+
+func TestCloudAccessAccountVerificationCreate(t *testing.T) {
+	pollInitialInterval = time.Millisecond
+	t.Cleanup(func() { pollInitialInterval = 5 * time.Second })
+
+	api := &testCloudAccessAPI{
+		shortName: "AWS",
+		accounts:  []gorhsm.EnabledProviderAccount{{Id: "012345678912"}},
+	}
+	polls := 0
+	api.beforeList = func() {
+		// the account is only verified on the third poll
+		polls++
+		api.accounts[0].Verified = gorhsm.PtrBool(polls >= 3)
+	}
+
+	ctx := context.Background()
+	r := &CloudAccessAccountVerificationResource{client: testAPIClient(t, api.handler(t))}
+
+	plan := testCloudAccessAccountVerificationPlan(t, r, "AWS", types.StringValue("c2lnbmF0dXJl"))
+	resp := &fwresource.CreateResponse{State: tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Raw.Type(), nil)}}
+
+	r.Create(ctx, fwresource.CreateRequest{Plan: plan}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+
+	want := []gorhsm.VerifyProviderAccountRequest{{Identity: "aWRlbnRpdHk=", Signature: "c2lnbmF0dXJl"}}
+	if !reflect.DeepEqual(api.verifications, want) {
+		t.Errorf("unexpected verification requests %+v", api.verifications)
+	}
+	if polls != 3 {
+		t.Errorf("expected 3 polls, got %d", polls)
+	}
+
+	var data CloudAccessAccountVerificationResourceModel
+	resp.Diagnostics.Append(resp.State.Get(ctx, &data)...)
+	if !data.Verified.ValueBool() || data.ID.ValueString() != "AWS:012345678912" {
+		t.Errorf("unexpected state %+v", data)
+	}
+}
+
+func TestCloudAccessAccountVerificationValidateConfig(t *testing.T) {
+	cases := map[string]struct {
+		shortName string
+		signature types.String
+		errors    int
+	}{
+		"signed":            {shortName: "AWS", signature: types.StringValue("c2lnbmF0dXJl")},
+		"missing signature": {shortName: "MSAZ", signature: types.StringNull(), errors: 1},
+		"unsigned":          {shortName: "GCE", signature: types.StringNull()},
+		"signed token":      {shortName: "GCE", signature: types.StringValue("c2lnbmF0dXJl"), errors: 1},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &CloudAccessAccountVerificationResource{}
+			plan := testCloudAccessAccountVerificationPlan(t, r, tc.shortName, tc.signature)
+
+			resp := &fwresource.ValidateConfigResponse{}
+			r.ValidateConfig(context.Background(), fwresource.ValidateConfigRequest{
+				Config: tfsdk.Config{Schema: plan.Schema, Raw: plan.Raw},
+			}, resp)
+
+			if got := resp.Diagnostics.ErrorsCount(); got != tc.errors {
+				t.Errorf("expected %d errors, got %d: %v", tc.errors, got, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+// testCloudAccessAccountVerificationPlan returns a plan to verify account
+// 012345678912.
+func testCloudAccessAccountVerificationPlan(t *testing.T, r *CloudAccessAccountVerificationResource, shortName string, signature types.String) tfsdk.Plan {
+	t.Helper()
+
+	return testPlan(t, r, &CloudAccessAccountVerificationResourceModel{
+		ID:                types.StringUnknown(),
+		AccountID:         types.StringValue("012345678912"),
+		ProviderShortName: types.StringValue(shortName),
+		Identity:          types.StringValue("aWRlbnRpdHk="),
+		Signature:         signature,
+		Verified:          types.BoolUnknown(),
+	})
+}
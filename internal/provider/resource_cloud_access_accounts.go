@@ -0,0 +1,704 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/umich-vci/gorhsm"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &CloudAccessAccountsResource{}
+var _ resource.ResourceWithImportState = &CloudAccessAccountsResource{}
+var _ resource.ResourceWithModifyPlan = &CloudAccessAccountsResource{}
+var _ resource.ResourceWithConfigValidators = &CloudAccessAccountsResource{}
+
+func NewCloudAccessAccountsResource() resource.Resource {
+	return &CloudAccessAccountsResource{}
+}
+
+// CloudAccessAccountsResource defines the resource implementation.
+type CloudAccessAccountsResource struct {
+	client *apiClient
+}
+
+// CloudAccessAccountsResourceModel describes the resource data model.
+type CloudAccessAccountsResourceModel struct {
+	ID                types.String   `tfsdk:"id"`
+	ProviderShortName types.String   `tfsdk:"provider_short_name"`
+	Accounts          types.Map      `tfsdk:"accounts"`
+	Timeouts          timeouts.Value `tfsdk:"timeouts"`
+}
+
+type CloudAccessAccountsEntryModel struct {
+	Nickname   types.String `tfsdk:"nickname"`
+	GoldImages types.Set    `tfsdk:"gold_images"`
+}
+
+func (m CloudAccessAccountsEntryModel) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"nickname":    types.StringType,
+		"gold_images": types.SetType{ElemType: types.StringType},
+	}
+}
+
+// cloudAccessAccountEntry is the configuration of a single account managed
+// by rhsm_cloud_access_accounts.
+type cloudAccessAccountEntry struct {
+	Nickname   string
+	GoldImages []string
+}
+
+// cloudAccessAccountsChanges are the API calls needed to move the accounts of
+// a cloud provider from one set of entries to another.
+type cloudAccessAccountsChanges struct {
+	// Remove are the IDs of accounts to remove. The API only removes one
+	// account per call.
+	Remove []string
+
+	// Add are the accounts to add in a single call.
+	Add []gorhsm.AddProviderAccount
+
+	// Rename maps the IDs of existing accounts to their new nickname. The API
+	// only updates one account per call.
+	Rename map[string]string
+
+	// GoldImages are the gold image requests to make, with accounts that need
+	// the same images grouped into one request.
+	GoldImages []gorhsm.EnableGoldImagesRequest
+}
+
+func (r *CloudAccessAccountsResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cloud_access_accounts"
+}
+
+func (r *CloudAccessAccountsResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Resource to manage entitlement for Red Hat Cloud Access for many accounts in a single cloud provider. " +
+			"Accounts are added and gold images are requested in batched API calls, which is much faster than using " +
+			"`rhsm_cloud_access_account` for each account. An account should not be managed by both resources.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The ID of the resource, which is the `provider_short_name`.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"provider_short_name": schema.StringAttribute{
+				Description: "The short name of the cloud provider that the accounts are in, such as \"AWS\", \"GCE\", or \"MSAZ\". This must be one of the cloud providers enabled for Cloud Access in your organization, which can be found with the `rhsm_cloud_access` data source.",
+				Required:    true,
+				Validators:  []validator.String{stringvalidator.NoneOf("")},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"accounts": schema.MapNestedAttribute{
+				Description: "The cloud accounts to request Red Hat Cloud Access for, keyed by account ID. For AWS this must be a 12 digit account ID, for MSAZ a subscription ID in GUID format, and for GCE the email address of a Google Group.",
+				Required:    true,
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+					mapvalidator.KeysAre(stringvalidator.NoneOf("")),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"nickname": schema.StringAttribute{
+							Description: "A nickname to help describe the account.",
+							Optional:    true,
+						},
+						"gold_images": schema.SetAttribute{
+							Description: "A list of gold images to request access to for the account. Images available to a cloud provider can be found with the `rhsm_cloud_access` data source. Once you request access to a gold image, it is not possible to disable access via the API. Removing an image shows a warning at plan time, and the image is kept in the plan when no other image is added to the account in the same change.",
+							Optional:    true,
+							Computed:    true,
+							Default:     setdefault.StaticValue(types.SetValueMust(types.StringType, []attr.Value{})),
+							ElementType: types.StringType,
+						},
+					},
+				},
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+func (r *CloudAccessAccountsResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		accountsValidator{},
+	}
+}
+
+func (r *CloudAccessAccountsResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*apiClient)
+	if !ok {
+		resp.Diagnostics.AddError("Failed to configure Cloud Access Accounts resource", "Invalid provider data")
+		return
+	}
+
+	r.client = client
+}
+
+func (r *CloudAccessAccountsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data CloudAccessAccountsResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := data.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	shortName := data.ProviderShortName.ValueString()
+	ctx = tflog.SetField(ctx, "provider_short_name", shortName)
+
+	data.ID = types.StringValue(shortName)
+
+	planned, diags := expandCloudAccessAccountEntries(ctx, data.Accounts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.applyChanges(ctx, shortName, diffCloudAccessAccounts(nil, planned), &resp.Diagnostics)
+
+	// save whatever was added, even if a later step failed, so the accounts
+	// are not lost from state
+	r.refresh(ctx, &data, !resp.Diagnostics.HasError(), &resp.Diagnostics)
+	if data.Accounts.IsNull() || len(data.Accounts.Elements()) == 0 {
+		if !resp.Diagnostics.HasError() {
+			// this should not happen since we just added them
+			resp.Diagnostics.AddError("Failed to find created Cloud Access Accounts", "No matching accounts were found")
+		}
+		return
+	}
+
+	tflog.Trace(ctx, "created a resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CloudAccessAccountsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data CloudAccessAccountsResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	ctx = tflog.SetField(ctx, "provider_short_name", data.ID.ValueString())
+
+	r.refresh(ctx, &data, false, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CloudAccessAccountsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state CloudAccessAccountsResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := data.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	shortName := state.ID.ValueString()
+	ctx = tflog.SetField(ctx, "provider_short_name", shortName)
+
+	prior, diags := expandCloudAccessAccountEntries(ctx, state.Accounts)
+	resp.Diagnostics.Append(diags...)
+	planned, diags := expandCloudAccessAccountEntries(ctx, data.Accounts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.applyChanges(ctx, shortName, diffCloudAccessAccounts(prior, planned), &resp.Diagnostics)
+
+	if resp.Diagnostics.HasError() {
+		// track every account that might exist after a partial update so the
+		// next plan picks up from where this one stopped
+		accounts := make(map[string]CloudAccessAccountsEntryModel)
+		resp.Diagnostics.Append(state.Accounts.ElementsAs(ctx, &accounts, false)...)
+		resp.Diagnostics.Append(data.Accounts.ElementsAs(ctx, &accounts, false)...)
+		data.Accounts, diags = types.MapValueFrom(ctx, types.ObjectType{AttrTypes: CloudAccessAccountsEntryModel{}.AttributeTypes()}, accounts)
+		resp.Diagnostics.Append(diags...)
+	}
+
+	// gold_images is left as planned after a successful update since images
+	// that were removed from it are still returned by the API
+	r.refresh(ctx, &data, !resp.Diagnostics.HasError(), &resp.Diagnostics)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CloudAccessAccountsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data CloudAccessAccountsResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := data.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	shortName := data.ID.ValueString()
+	ctx = tflog.SetField(ctx, "provider_short_name", shortName)
+
+	prior, diags := expandCloudAccessAccountEntries(ctx, data.Accounts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.applyChanges(ctx, shortName, diffCloudAccessAccounts(prior, nil), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		// keep the accounts that could not be removed in state
+		r.refresh(ctx, &data, false, &resp.Diagnostics)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+func (r *CloudAccessAccountsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan CloudAccessAccountsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state CloudAccessAccountsResourceModel
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		r.modifyPlanGoldImages(ctx, plan, state, resp)
+	}
+
+	// the provider has not been configured yet
+	if r.client == nil {
+		return
+	}
+
+	checkProviderShortName(ctx, r.client, plan.ProviderShortName, state.ProviderShortName, &resp.Diagnostics)
+}
+
+// modifyPlanGoldImages warns about gold images that were removed from an
+// account. Since access cannot be removed, the prior images of an account
+// are kept in the plan when no new images were added so that the plan
+// converges.
+func (r *CloudAccessAccountsResource) modifyPlanGoldImages(ctx context.Context, plan CloudAccessAccountsResourceModel, state CloudAccessAccountsResourceModel, resp *resource.ModifyPlanResponse) {
+	if plan.Accounts.IsUnknown() || state.Accounts.IsNull() || state.Accounts.IsUnknown() {
+		return
+	}
+
+	planned := make(map[string]CloudAccessAccountsEntryModel)
+	prior := make(map[string]CloudAccessAccountsEntryModel)
+	resp.Diagnostics.Append(plan.Accounts.ElementsAs(ctx, &planned, false)...)
+	resp.Diagnostics.Append(state.Accounts.ElementsAs(ctx, &prior, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	accountIDs := make([]string, 0, len(planned))
+	for accountID := range planned {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+
+	kept := false
+	for _, accountID := range accountIDs {
+		entry := planned[accountID]
+		priorEntry, ok := prior[accountID]
+		if !ok || entry.GoldImages.IsUnknown() || priorEntry.GoldImages.IsNull() || priorEntry.GoldImages.IsUnknown() {
+			continue
+		}
+
+		var plannedImages, priorImages []string
+		resp.Diagnostics.Append(entry.GoldImages.ElementsAs(ctx, &plannedImages, false)...)
+		resp.Diagnostics.Append(priorEntry.GoldImages.ElementsAs(ctx, &priorImages, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		removed, keepPrior := removedGoldImages(plannedImages, priorImages)
+		if len(removed) == 0 {
+			continue
+		}
+
+		resp.Diagnostics.AddAttributeWarning(path.Root("accounts").AtMapKey(accountID).AtName("gold_images"),
+			"Gold image access cannot be removed",
+			fmt.Sprintf("Access to gold images cannot be removed through the RHSM API, so access to %s will remain enabled for account %s. "+
+				"Add the images back to gold_images to keep the configuration in sync with RHSM.", quoteList(removed), accountID))
+
+		if keepPrior {
+			entry.GoldImages = priorEntry.GoldImages
+			planned[accountID] = entry
+			kept = true
+		}
+	}
+
+	if !kept {
+		return
+	}
+
+	accounts, diags := types.MapValueFrom(ctx, types.ObjectType{AttrTypes: CloudAccessAccountsEntryModel{}.AttributeTypes()}, planned)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("accounts"), accounts)...)
+}
+
+// ImportState imports every account enabled for the cloud provider named by
+// the ID.
+func (r *CloudAccessAccountsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("provider_short_name"), req.ID)...)
+}
+
+// applyChanges makes the API calls in changes, stopping at the first one
+// that fails.
+func (r *CloudAccessAccountsResource) applyChanges(ctx context.Context, shortName string, changes cloudAccessAccountsChanges, diags *diag.Diagnostics) {
+	client := r.client.Client
+
+	for _, accountID := range changes.Remove {
+		remove := gorhsm.RemoveProviderAccountRequest{Id: accountID}
+
+		rpa, err := client.CloudaccessAPI.RemoveProviderAccount(ctx, shortName).Account(remove).Execute()
+		r.client.CloudAccess.invalidate()
+		if err != nil && alreadyRemoved(rpa) {
+			tflog.Debug(ctx, "cloud access account was already removed", map[string]interface{}{"account_id": accountID, "error": err.Error()})
+			err = nil
+		}
+		if err != nil {
+			if addTimeoutError(ctx, diags, fmt.Sprintf("remove cloud access account %s", accountID)) {
+				return
+			}
+			addAPIError(diags, fmt.Sprintf("Failed to remove Cloud Access Account %s", accountID), rpa, err)
+			return
+		}
+	}
+
+	if len(changes.Add) > 0 {
+		tflog.Debug(ctx, "Adding cloud access accounts", map[string]interface{}{"count": len(changes.Add)})
+
+		apa, err := client.CloudaccessAPI.AddProviderAccounts(ctx, shortName).Account(changes.Add).Execute()
+		r.client.CloudAccess.invalidate()
+		if apa != nil {
+			apa.Body.Close()
+		}
+		if err != nil && requestOutcomeUnknown(apa, err) && r.accountsAdded(ctx, shortName, changes.Add) {
+			tflog.Debug(ctx, "cloud access accounts were added despite an error response", map[string]interface{}{"error": err.Error()})
+			err = nil
+		}
+		if err != nil {
+			if addTimeoutError(ctx, diags, "add the cloud access accounts") {
+				return
+			}
+			addAPIError(diags, "Failed to add Cloud Access Accounts", apa, err)
+			return
+		}
+	}
+
+	renamed := make([]string, 0, len(changes.Rename))
+	for accountID := range changes.Rename {
+		renamed = append(renamed, accountID)
+	}
+	sort.Strings(renamed)
+
+	for _, accountID := range renamed {
+		account := gorhsm.UpdateProviderAccountRequest{Nickname: changes.Rename[accountID]}
+
+		upa, err := client.CloudaccessAPI.UpdateProviderAccount(ctx, shortName, accountID).Account(account).Execute()
+		r.client.CloudAccess.invalidate()
+		if err != nil {
+			if addTimeoutError(ctx, diags, fmt.Sprintf("update the nickname of cloud access account %s", accountID)) {
+				return
+			}
+			addAPIError(diags, fmt.Sprintf("Failed to update Cloud Access Account %s nickname", accountID), upa, err)
+			return
+		}
+	}
+
+	for _, gi := range changes.GoldImages {
+		egi, err := client.CloudaccessAPI.EnableGoldImages(ctx, shortName).GoldImages(gi).Execute()
+		r.client.CloudAccess.invalidate()
+		if egi != nil {
+			egi.Body.Close()
+		}
+		if err != nil && requestOutcomeUnknown(egi, err) && r.goldImagesRequested(ctx, shortName, gi) {
+			tflog.Debug(ctx, "gold images were requested despite an error response", map[string]interface{}{"error": err.Error()})
+			err = nil
+		}
+		if err != nil {
+			if addTimeoutError(ctx, diags, "enable gold images") {
+				return
+			}
+			addAPIError(diags, fmt.Sprintf("Failed to enable gold images %s for accounts %s",
+				strings.Join(gi.Images, ", "), strings.Join(gi.Accounts, ", ")), egi, err)
+			return
+		}
+	}
+}
+
+// refresh updates the accounts in data from the API. Accounts that are no
+// longer enabled are dropped, and if data has no accounts yet, such as after
+// an import, every account enabled for the cloud provider is added. If
+// keepGoldImages is set, the gold_images already in data are kept, since
+// images that were removed from the plan are still returned by the API.
+func (r *CloudAccessAccountsResource) refresh(ctx context.Context, data *CloudAccessAccountsResourceModel, keepGoldImages bool, diags *diag.Diagnostics) {
+	caps, lecap, err := r.client.CloudAccess.get(ctx)
+	if err != nil {
+		if addTimeoutError(ctx, diags, "list enabled cloud access providers") {
+			return
+		}
+		addAPIError(diags, "Failed to list enabled cloud access providers", lecap, err)
+		return
+	}
+
+	prior := make(map[string]CloudAccessAccountsEntryModel)
+	if !data.Accounts.IsNull() && !data.Accounts.IsUnknown() {
+		diags.Append(data.Accounts.ElementsAs(ctx, &prior, false)...)
+		if diags.HasError() {
+			return
+		}
+	}
+	importing := data.Accounts.IsNull()
+
+	accounts := make(map[string]CloudAccessAccountsEntryModel)
+	for _, x := range caps.GetBody() {
+		if x.GetShortName() != data.ID.ValueString() {
+			continue
+		}
+
+		for _, y := range x.GetAccounts() {
+			priorEntry, ok := prior[y.Id]
+			if !ok && !importing {
+				continue
+			}
+
+			goldImages := []attr.Value{}
+			for _, z := range y.GetGoldImageStatus() {
+				goldImages = append(goldImages, types.StringValue(z.GetName()))
+			}
+
+			entry := CloudAccessAccountsEntryModel{
+				Nickname:   nicknameValue(y.GetNickname(), priorEntry.Nickname),
+				GoldImages: types.SetValueMust(types.StringType, goldImages),
+			}
+			if keepGoldImages && ok {
+				entry.GoldImages = priorEntry.GoldImages
+			}
+
+			accounts[y.Id] = entry
+		}
+	}
+
+	accountsMap, d := types.MapValueFrom(ctx, types.ObjectType{AttrTypes: CloudAccessAccountsEntryModel{}.AttributeTypes()}, accounts)
+	diags.Append(d...)
+	if d.HasError() {
+		return
+	}
+
+	data.ProviderShortName = data.ID
+	data.Accounts = accountsMap
+}
+
+// accountsAdded reports whether all of accounts are enabled for Cloud Access.
+func (r *CloudAccessAccountsResource) accountsAdded(ctx context.Context, shortName string, accounts []gorhsm.AddProviderAccount) bool {
+	for _, account := range accounts {
+		if !cloudAccessAccountEnabled(ctx, r.client, shortName, account.GetId()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// goldImagesRequested reports whether access to the images in gi has already
+// been requested for all of its accounts.
+func (r *CloudAccessAccountsResource) goldImagesRequested(ctx context.Context, shortName string, gi gorhsm.EnableGoldImagesRequest) bool {
+	for _, accountID := range gi.Accounts {
+		if !goldImagesRequested(ctx, r.client, shortName, accountID, gi.Images) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// expandCloudAccessAccountEntries converts an accounts map into entries keyed
+// by account ID.
+func expandCloudAccessAccountEntries(ctx context.Context, accounts types.Map) (map[string]cloudAccessAccountEntry, diag.Diagnostics) {
+	var d diag.Diagnostics
+
+	models := make(map[string]CloudAccessAccountsEntryModel)
+	d.Append(accounts.ElementsAs(ctx, &models, false)...)
+	if d.HasError() {
+		return nil, d
+	}
+
+	entries := make(map[string]cloudAccessAccountEntry, len(models))
+	for accountID, m := range models {
+		entry := cloudAccessAccountEntry{Nickname: m.Nickname.ValueString()}
+		if !m.GoldImages.IsNull() && !m.GoldImages.IsUnknown() {
+			d.Append(m.GoldImages.ElementsAs(ctx, &entry.GoldImages, false)...)
+		}
+		entries[accountID] = entry
+	}
+
+	return entries, d
+}
+
+// diffCloudAccessAccounts returns the API calls needed to move from the prior
+// entries to the planned ones. Gold images that are no longer planned are not
+// disabled because the API does not support it.
+func diffCloudAccessAccounts(prior map[string]cloudAccessAccountEntry, planned map[string]cloudAccessAccountEntry) cloudAccessAccountsChanges {
+	changes := cloudAccessAccountsChanges{Rename: map[string]string{}}
+
+	for accountID := range prior {
+		if _, ok := planned[accountID]; !ok {
+			changes.Remove = append(changes.Remove, accountID)
+		}
+	}
+	sort.Strings(changes.Remove)
+
+	accountIDs := make([]string, 0, len(planned))
+	for accountID := range planned {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+
+	// group accounts by the set of images they need so each set is
+	// requested once
+	groups := make(map[string]*gorhsm.EnableGoldImagesRequest)
+	var groupKeys []string
+
+	for _, accountID := range accountIDs {
+		entry := planned[accountID]
+		priorEntry, exists := prior[accountID]
+
+		if !exists {
+			account := gorhsm.AddProviderAccount{Id: gorhsm.PtrString(accountID)}
+			if entry.Nickname != "" {
+				account.Nickname = gorhsm.PtrString(entry.Nickname)
+			}
+			changes.Add = append(changes.Add, account)
+		} else if entry.Nickname != priorEntry.Nickname {
+			changes.Rename[accountID] = entry.Nickname
+		}
+
+		var images []string
+		for _, image := range entry.GoldImages {
+			if !slices.Contains(priorEntry.GoldImages, image) {
+				images = append(images, image)
+			}
+		}
+		if len(images) == 0 {
+			continue
+		}
+		sort.Strings(images)
+
+		key := strings.Join(images, "\x00")
+		if _, ok := groups[key]; !ok {
+			groups[key] = &gorhsm.EnableGoldImagesRequest{Images: images}
+			groupKeys = append(groupKeys, key)
+		}
+		groups[key].Accounts = append(groups[key].Accounts, accountID)
+	}
+
+	sort.Strings(groupKeys)
+	for _, key := range groupKeys {
+		changes.GoldImages = append(changes.GoldImages, *groups[key])
+	}
+
+	return changes
+}
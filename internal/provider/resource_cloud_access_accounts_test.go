@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/umich-vci/gorhsm"
+)
+
+func TestAccResourceCloudAccessAccounts(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceCloudAccessAccounts,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"rhsm_cloud_access_accounts.aws_test_accounts", "provider_short_name", "AWS"),
+					resource.TestCheckResourceAttr(
+						"rhsm_cloud_access_accounts.aws_test_accounts", "accounts.%", "2"),
+					resource.TestCheckResourceAttr(
+						"rhsm_cloud_access_accounts.aws_test_accounts", "accounts.012345678913.nickname", "Terraform Acceptance Test AWS Account 1"),
+				),
+			},
+		},
+	})
+}
+
+func TestDiffCloudAccessAccounts(t *testing.T) {
+	prior := map[string]cloudAccessAccountEntry{
+		"1": {Nickname: "one", GoldImages: []string{"RHEL"}},
+		"2": {Nickname: "two"},
+		"3": {Nickname: "three"},
+	}
+	planned := map[string]cloudAccessAccountEntry{
+		"1": {Nickname: "one", GoldImages: []string{"RHEL", "RHEL-HA"}},
+		"2": {Nickname: "renamed"},
+		"4": {Nickname: "four", GoldImages: []string{"RHEL-HA"}},
+		"5": {GoldImages: []string{"RHEL", "RHEL-HA"}},
+	}
+
+	got := diffCloudAccessAccounts(prior, planned)
+
+	want := cloudAccessAccountsChanges{
+		Remove: []string{"3"},
+		Add: []gorhsm.AddProviderAccount{
+			{Id: gorhsm.PtrString("4"), Nickname: gorhsm.PtrString("four")},
+			{Id: gorhsm.PtrString("5")},
+		},
+		Rename: map[string]string{"2": "renamed"},
+		GoldImages: []gorhsm.EnableGoldImagesRequest{
+			{Accounts: []string{"5"}, Images: []string{"RHEL", "RHEL-HA"}},
+			{Accounts: []string{"1", "4"}, Images: []string{"RHEL-HA"}},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestDiffCloudAccessAccountsDestroy(t *testing.T) {
+	prior := map[string]cloudAccessAccountEntry{
+		"2": {},
+		"1": {},
+	}
+
+	got := diffCloudAccessAccounts(prior, nil)
+
+	if !reflect.DeepEqual(got.Remove, []string{"1", "2"}) {
+		t.Fatalf("expected accounts 1 and 2 to be removed, got %v", got.Remove)
+	}
+	if len(got.Add) != 0 || len(got.Rename) != 0 || len(got.GoldImages) != 0 {
+		t.Fatalf("expected only removals, got %+v", got)
+	}
+}
+
+func TestCloudAccessAccountsCRUD(t *testing.T) {
+	api := &testCloudAccessAPI{shortName: "AWS"}
+
+	ctx := context.Background()
+	r := &CloudAccessAccountsResource{client: testAPIClient(t, api.handler(t))}
+
+	created := testCloudAccessAccountsPlan(t, r, map[string]CloudAccessAccountsEntryModel{
+		"012345678912": {
+			Nickname:   types.StringNull(),
+			GoldImages: types.SetValueMust(types.StringType, []attr.Value{types.StringValue("RHEL"), types.StringValue("RHEL-HA")}),
+		},
+	})
+
+	createResp := &fwresource.CreateResponse{State: tfsdk.State{Schema: created.Schema, Raw: tftypes.NewValue(created.Raw.Type(), nil)}}
+	r.Create(ctx, fwresource.CreateRequest{Plan: created}, createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatal(createResp.Diagnostics)
+	}
+	if !createResp.State.Raw.Equal(created.Raw) {
+		t.Fatalf("expected the created state to match the plan, got %s", createResp.State.Raw)
+	}
+
+	// removing an image keeps access to it, but the state must still match
+	// the plan or Terraform reports an inconsistent result
+	updated := testCloudAccessAccountsPlan(t, r, map[string]CloudAccessAccountsEntryModel{
+		"012345678912": {
+			Nickname:   types.StringNull(),
+			GoldImages: types.SetValueMust(types.StringType, []attr.Value{types.StringValue("RHEL")}),
+		},
+		"123456789012": {
+			Nickname:   types.StringValue("dev"),
+			GoldImages: types.SetValueMust(types.StringType, []attr.Value{types.StringValue("RHEL")}),
+		},
+	})
+
+	updateResp := &fwresource.UpdateResponse{State: tfsdk.State{Schema: updated.Schema, Raw: updated.Raw}}
+	r.Update(ctx, fwresource.UpdateRequest{Plan: updated, State: createResp.State}, updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatal(updateResp.Diagnostics)
+	}
+	if !updateResp.State.Raw.Equal(updated.Raw) {
+		t.Fatalf("expected the updated state to match the plan, got %s", updateResp.State.Raw)
+	}
+
+	if !slices.Equal(api.added, []string{"012345678912", "123456789012"}) {
+		t.Errorf("expected both accounts to be added, got %v", api.added)
+	}
+	if !slices.Equal(api.requested, []string{"RHEL", "RHEL-HA", "RHEL"}) {
+		t.Errorf("expected only the new account to request RHEL on update, got %v", api.requested)
+	}
+
+	// RHEL-HA is still returned by the API, so it comes back on refresh and
+	// the next plan keeps it instead of planning an update that does nothing
+	readResp := &fwresource.ReadResponse{State: updateResp.State}
+	r.Read(ctx, fwresource.ReadRequest{State: updateResp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatal(readResp.Diagnostics)
+	}
+
+	planResp := &fwresource.ModifyPlanResponse{Plan: updated}
+	r.ModifyPlan(ctx, fwresource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: updated.Schema, Raw: updated.Raw},
+		Plan:   updated,
+		State:  readResp.State,
+	}, planResp)
+	if planResp.Diagnostics.HasError() {
+		t.Fatal(planResp.Diagnostics)
+	}
+	if got := planResp.Diagnostics.WarningsCount(); got != 1 {
+		t.Errorf("expected 1 warning about the removed image, got %d: %v", got, planResp.Diagnostics)
+	}
+	if !planResp.Plan.Raw.Equal(readResp.State.Raw) {
+		t.Fatalf("expected no changes to be planned after the refresh, got %s", planResp.Plan.Raw)
+	}
+
+	deleteResp := &fwresource.DeleteResponse{State: readResp.State}
+	r.Delete(ctx, fwresource.DeleteRequest{State: readResp.State}, deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Fatal(deleteResp.Diagnostics)
+	}
+	if !deleteResp.State.Raw.IsNull() {
+		t.Error("expected the resource to be removed from state")
+	}
+	if len(api.accounts) != 0 {
+		t.Errorf("expected every account to be removed, got %v", api.accounts)
+	}
+}
+
+func testCloudAccessAccountsPlan(t *testing.T, r *CloudAccessAccountsResource, accounts map[string]CloudAccessAccountsEntryModel) tfsdk.Plan {
+	t.Helper()
+
+	accountsMap, diags := types.MapValueFrom(context.Background(), types.ObjectType{AttrTypes: CloudAccessAccountsEntryModel{}.AttributeTypes()}, accounts)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	return testPlan(t, r, &CloudAccessAccountsResourceModel{
+		ID:                types.StringValue("AWS"),
+		ProviderShortName: types.StringValue("AWS"),
+		Accounts:          accountsMap,
+	})
+}
+
+const testAccResourceCloudAccessAccounts = `
+resource "rhsm_cloud_access_accounts" "aws_test_accounts" {
+	provider_short_name = "AWS"
+
+	accounts = {
+		"012345678913" = {
+			nickname = "Terraform Acceptance Test AWS Account 1"
+		}
+		"012345678914" = {
+			nickname    = "Terraform Acceptance Test AWS Account 2"
+			gold_images = ["RHEL"]
+		}
+	}
+}
+`
@@ -0,0 +1,390 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/umich-vci/gorhsm"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &CloudAccessGoldImageResource{}
+var _ resource.ResourceWithImportState = &CloudAccessGoldImageResource{}
+var _ resource.ResourceWithModifyPlan = &CloudAccessGoldImageResource{}
+var _ resource.ResourceWithConfigValidators = &CloudAccessGoldImageResource{}
+
+func NewCloudAccessGoldImageResource() resource.Resource {
+	return &CloudAccessGoldImageResource{}
+}
+
+// CloudAccessGoldImageResource defines the resource implementation.
+type CloudAccessGoldImageResource struct {
+	client *apiClient
+}
+
+// CloudAccessGoldImageResourceModel describes the resource data model.
+type CloudAccessGoldImageResourceModel struct {
+	ID                types.String   `tfsdk:"id"`
+	AccountID         types.String   `tfsdk:"account_id"`
+	ProviderShortName types.String   `tfsdk:"provider_short_name"`
+	Image             types.String   `tfsdk:"image"`
+	Description       types.String   `tfsdk:"description"`
+	Status            types.String   `tfsdk:"status"`
+	DateRequested     types.String   `tfsdk:"date_requested"`
+	Timeouts          timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (r *CloudAccessGoldImageResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cloud_access_gold_image"
+}
+
+func (r *CloudAccessGoldImageResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Resource to request access to a single Red Hat Cloud Access gold image for an account. " +
+			"The account must already be enabled for Cloud Access, for example with the `rhsm_cloud_access_account` resource. " +
+			"Access to a gold image cannot be removed through the API, so destroying this resource only removes it from state. " +
+			"Leave `gold_images` unset on a `rhsm_cloud_access_account` whose images are managed with this resource, otherwise " +
+			"each plan reports the images it does not manage as removed.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The ID of the gold image request in the format `provider_short_name:account_id:image`.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"account_id": schema.StringAttribute{
+				Description: "The ID of the cloud account to request the gold image for. For AWS this must be a 12 digit account ID, for MSAZ a subscription ID in GUID format, and for GCE the email address of a Google Group.",
+				Required:    true,
+				Validators:  []validator.String{stringvalidator.NoneOf("")},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"provider_short_name": schema.StringAttribute{
+				Description: "The short name of the cloud provider that the `account_id` is in, such as \"AWS\", \"GCE\", or \"MSAZ\".",
+				Required:    true,
+				Validators:  []validator.String{stringvalidator.NoneOf("")},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"image": schema.StringAttribute{
+				MarkdownDescription: "The name of the gold image to request access to. The gold images that are available for a cloud provider " +
+					"can be found with the `rhsm_cloud_access` data source.",
+				Required:   true,
+				Validators: []validator.String{stringvalidator.NoneOf("")},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"description": schema.StringAttribute{
+				Description: "The description of the gold image.",
+				Computed:    true,
+			},
+			"status": schema.StringAttribute{
+				Description: "The status of the gold image request, such as \"Requested\", \"Granted\", or \"Failed\".",
+				Computed:    true,
+			},
+			"date_requested": schema.StringAttribute{
+				MarkdownDescription: "The time the gold image was requested by this resource, in RFC 3339 format. " +
+					"The RHSM API does not report when a gold image was requested, so this is null for imported gold images and for images that had already been requested when the resource was created.",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+			}),
+		},
+	}
+}
+
+func (r *CloudAccessGoldImageResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		accountIDValidator{},
+	}
+}
+
+func (r *CloudAccessGoldImageResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*apiClient)
+	if !ok {
+		resp.Diagnostics.AddError("Failed to configure Cloud Access Gold Image resource", "Invalid provider data")
+		return
+	}
+
+	r.client = client
+}
+
+func (r *CloudAccessGoldImageResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data CloudAccessGoldImageResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := data.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	shortName := data.ProviderShortName.ValueString()
+	accountID := data.AccountID.ValueString()
+	image := data.Image.ValueString()
+
+	ctx = tflog.SetField(ctx, "provider_short_name", shortName)
+	ctx = tflog.SetField(ctx, "account_id", accountID)
+	ctx = tflog.SetField(ctx, "image", image)
+
+	data.ID = types.StringValue(fmt.Sprintf("%s:%s:%s", shortName, accountID, image))
+
+	account, diags := r.readAccount(ctx, shortName, accountID)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if account == nil {
+		resp.Diagnostics.AddError("Cloud Access Account not found",
+			fmt.Sprintf("The account %s is not enabled for Cloud Access in %s. Enable it before requesting gold images for it.", accountID, shortName))
+		return
+	}
+
+	// the image may already have been requested by another resource or in the
+	// console, and requesting it again is not needed
+	data.DateRequested = types.StringNull()
+	status := findGoldImageStatus(account.GetGoldImageStatus(), image)
+	if status == nil {
+		gi := gorhsm.EnableGoldImagesRequest{
+			Accounts: []string{accountID},
+			Images:   []string{image},
+		}
+
+		egi, err := r.client.Client.CloudaccessAPI.EnableGoldImages(ctx, shortName).GoldImages(gi).Execute()
+		r.client.CloudAccess.invalidate()
+		if egi != nil {
+			defer egi.Body.Close()
+		}
+		if err != nil && requestOutcomeUnknown(egi, err) && goldImagesRequested(ctx, r.client, shortName, accountID, gi.Images) {
+			tflog.Debug(ctx, "gold image was requested despite an error response", map[string]interface{}{"error": err.Error()})
+			err = nil
+		}
+		if err != nil {
+			if addTimeoutError(ctx, &resp.Diagnostics, "enable gold images") {
+				return
+			}
+			addAPIError(&resp.Diagnostics, "Failed to enable gold image", egi, err)
+			return
+		}
+
+		data.DateRequested = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+
+		account, diags = r.readAccount(ctx, shortName, accountID)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if account != nil {
+			status = findGoldImageStatus(account.GetGoldImageStatus(), image)
+		}
+	}
+
+	if status != nil {
+		data.Description = types.StringValue(status.GetDescription())
+		data.Status = types.StringValue(status.GetStatus())
+	} else {
+		// the request was accepted but has not shown up yet
+		data.Description = types.StringNull()
+		data.Status = types.StringValue("Requested")
+	}
+
+	tflog.Trace(ctx, "created a resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CloudAccessGoldImageResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data CloudAccessGoldImageResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	shortName, accountID, image, err := resourceCloudAccessGoldImageSplitID(data.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to parse Cloud Access Gold Image resource ID", err.Error())
+		return
+	}
+
+	ctx = tflog.SetField(ctx, "provider_short_name", shortName)
+	ctx = tflog.SetField(ctx, "account_id", accountID)
+	ctx = tflog.SetField(ctx, "image", image)
+
+	account, diags := r.readAccount(ctx, shortName, accountID)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var status *gorhsm.GoldImageStatus
+	if account != nil {
+		status = findGoldImageStatus(account.GetGoldImageStatus(), image)
+	}
+
+	// the account was removed from Cloud Access along with its gold images
+	if status == nil {
+		tflog.Debug(ctx, "gold image was not found")
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	data.ProviderShortName = types.StringValue(shortName)
+	data.AccountID = types.StringValue(accountID)
+	data.Image = types.StringValue(image)
+	data.Description = types.StringValue(status.GetDescription())
+	data.Status = types.StringValue(status.GetStatus())
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CloudAccessGoldImageResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state CloudAccessGoldImageResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// every other attribute requires replacement, so only the timeouts can
+	// change here
+	data.Description = state.Description
+	data.Status = state.Status
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CloudAccessGoldImageResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data CloudAccessGoldImageResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the API cannot revoke access to a gold image
+	resp.Diagnostics.AddWarning("Gold image access was not removed",
+		fmt.Sprintf("The RHSM API cannot remove access to a gold image, so access to %s for account %s in %s was only removed from the Terraform state. "+
+			"Contact Red Hat support to have access to the gold image removed.",
+			data.Image.ValueString(), data.AccountID.ValueString(), data.ProviderShortName.ValueString()))
+}
+
+func (r *CloudAccessGoldImageResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check when the resource is being destroyed or the provider
+	// has not been configured yet
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var planned, prior types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("provider_short_name"), &planned)...)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("provider_short_name"), &prior)...)
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	checkProviderShortName(ctx, r.client, planned, prior, &resp.Diagnostics)
+}
+
+func (r *CloudAccessGoldImageResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if _, _, _, err := resourceCloudAccessGoldImageSplitID(req.ID); err != nil {
+		resp.Diagnostics.AddError("Failed to parse Cloud Access Gold Image resource ID", err.Error())
+		return
+	}
+
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// readAccount returns the account enabled for Cloud Access, or nil if there
+// is no such account.
+func (r *CloudAccessGoldImageResource) readAccount(ctx context.Context, shortName string, accountID string) (*gorhsm.EnabledProviderAccount, diag.Diagnostics) {
+	var d diag.Diagnostics
+
+	caps, lecap, err := r.client.CloudAccess.get(ctx)
+	if err != nil {
+		if addTimeoutError(ctx, &d, "list enabled cloud access providers") {
+			return nil, d
+		}
+		addAPIError(&d, "Failed to list enabled cloud access providers", lecap, err)
+		return nil, d
+	}
+
+	return findProviderAccount(caps, shortName, accountID), d
+}
+
+// resourceCloudAccessGoldImageSplitID splits an ID in the format
+// provider_short_name:account_id:image. The image name is last since it is
+// the only part that may contain a colon.
+func resourceCloudAccessGoldImageSplitID(id string) (shortName string, accountID string, image string, err error) {
+	splitID := strings.SplitN(id, ":", 3)
+
+	if len(splitID) != 3 || splitID[0] == "" || splitID[1] == "" || splitID[2] == "" {
+		return "", "", "", fmt.Errorf("the Cloud Access Gold Image ID %s must be in the format provider_short_name:account_id:image", id)
+	}
+
+	return splitID[0], splitID[1], splitID[2], nil
+}
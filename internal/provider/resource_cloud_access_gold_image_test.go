@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/umich-vci/gorhsm"
+)
+
+func TestCloudAccessGoldImageCreate(t *testing.T) {
+	api := &testCloudAccessAPI{
+		shortName: "AWS",
+		accounts: []gorhsm.EnabledProviderAccount{{
+			Id: "012345678912",
+			GoldImageStatus: []gorhsm.GoldImageStatus{
+				{Name: gorhsm.PtrString("RHEL"), Description: gorhsm.PtrString("Red Hat Enterprise Linux"), Status: gorhsm.PtrString("Granted")},
+			},
+		}},
+	}
+
+	ctx := context.Background()
+	r := &CloudAccessGoldImageResource{client: testAPIClient(t, api.handler(t))}
+
+	cases := []struct {
+		image        string
+		expectStatus string
+		expectCalls  int
+	}{
+		// already granted, so it is not requested again
+		{image: "RHEL", expectStatus: "Granted", expectCalls: 0},
+		{image: "RHEL-HA", expectStatus: "Requested", expectCalls: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.image, func(t *testing.T) {
+			api.requested = nil
+
+			plan := testCloudAccessGoldImagePlan(t, r, c.image)
+			resp := &fwresource.CreateResponse{State: tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Raw.Type(), nil)}}
+
+			r.Create(ctx, fwresource.CreateRequest{Plan: plan}, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatal(resp.Diagnostics)
+			}
+
+			if len(api.requested) != c.expectCalls || (c.expectCalls > 0 && !slices.Equal(api.requested, []string{c.image})) {
+				t.Fatalf("expected %d requests for %s, got %v", c.expectCalls, c.image, api.requested)
+			}
+
+			var data CloudAccessGoldImageResourceModel
+			resp.Diagnostics.Append(resp.State.Get(ctx, &data)...)
+			if data.ID.ValueString() != "AWS:012345678912:"+c.image {
+				t.Errorf("unexpected ID %s", data.ID)
+			}
+			if data.Status.ValueString() != c.expectStatus {
+				t.Errorf("expected status %s, got %s", c.expectStatus, data.Status)
+			}
+			// only an image requested by the resource has a date_requested
+			if requested := c.expectCalls > 0; data.DateRequested.IsNull() == requested || data.DateRequested.IsUnknown() {
+				t.Errorf("expected date_requested to be set only when the image was requested, got %s", data.DateRequested)
+			}
+		})
+	}
+}
+
+func TestResourceCloudAccessGoldImageSplitID(t *testing.T) {
+	cases := map[string]struct {
+		id        string
+		expect    []string
+		expectErr bool
+	}{
+		"valid":            {id: "AWS:012345678912:RHEL", expect: []string{"AWS", "012345678912", "RHEL"}},
+		"image with colon": {id: "GCE:group@example.com:RHEL:8", expect: []string{"GCE", "group@example.com", "RHEL:8"}},
+		"missing image":    {id: "AWS:012345678912", expectErr: true},
+		"empty account":    {id: "AWS::RHEL", expectErr: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			shortName, accountID, image, err := resourceCloudAccessGoldImageSplitID(c.id)
+			if c.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := []string{shortName, accountID, image}; !slices.Equal(got, c.expect) {
+				t.Errorf("expected %v, got %v", c.expect, got)
+			}
+		})
+	}
+}
+
+func testCloudAccessGoldImagePlan(t *testing.T, r *CloudAccessGoldImageResource, image string) tfsdk.Plan {
+	t.Helper()
+
+	return testPlan(t, r, &CloudAccessGoldImageResourceModel{
+		ID:                types.StringUnknown(),
+		AccountID:         types.StringValue("012345678912"),
+		ProviderShortName: types.StringValue("AWS"),
+		Image:             types.StringValue(image),
+		Description:       types.StringUnknown(),
+		Status:            types.StringUnknown(),
+		DateRequested:     types.StringUnknown(),
+	})
+}
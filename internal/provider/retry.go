@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultMaxRetries is how many times a throttled or failed request is
+	// retried when max_retries is not configured.
+	defaultMaxRetries = 4
+
+	// defaultRetryMaxWait is the longest wait between retries when
+	// retry_max_wait is not configured.
+	defaultRetryMaxWait = 30 * time.Second
+
+	// retryMinWait is the wait before the first retry, doubled for each
+	// retry after that.
+	retryMinWait = time.Second
+
+	// retryMaxDoublings caps how many times retryMinWait is doubled, since
+	// shifting it much further overflows a time.Duration.
+	retryMaxDoublings = 30
+)
+
+// outcomeUnknownError wraps a network error for a non-idempotent request that
+// may or may not have been processed by the API.
+type outcomeUnknownError struct {
+	err error
+}
+
+func (e *outcomeUnknownError) Error() string {
+	return "the outcome of the request is unknown: " + e.err.Error()
+}
+
+func (e *outcomeUnknownError) Unwrap() error {
+	return e.err
+}
+
+// retryTransport is an http.RoundTripper that retries throttled and transient
+// failures with exponential backoff and jitter. Requests that are not
+// idempotent are only retried when the API has clearly not processed them.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	maxWait    time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+
+		if !shouldRetry(req, resp, err) || attempt >= t.maxRetries || (req.Body != nil && req.GetBody == nil) {
+			if err != nil && !isIdempotent(req.Method) && req.Context().Err() == nil {
+				err = &outcomeUnknownError{err: err}
+			}
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns how long to wait before retrying after the given attempt.
+// A Retry-After header sent by the API takes precedence over the calculated
+// backoff, but neither may exceed maxWait.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(wait, t.maxWait)
+		}
+	}
+
+	wait := min(retryMinWait<<min(attempt, retryMaxDoublings), t.maxWait)
+
+	// full jitter over the upper half keeps parallel resources from
+	// retrying in lockstep
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// shouldRetry reports whether a request that returned resp or err can be
+// safely sent again.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		// the request may have reached the API before the connection failed
+		return isIdempotent(req.Method)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		// a gateway error does not tell us whether the API processed it
+		return isIdempotent(req.Method)
+	}
+
+	return false
+}
+
+// requestOutcomeUnknown reports whether a failed request may still have been
+// processed by the API. Callers should check with a follow-up read rather
+// than assume the request failed.
+func requestOutcomeUnknown(resp *http.Response, err error) bool {
+	var unknown *outcomeUnknownError
+	if errors.As(err, &unknown) {
+		return true
+	}
+
+	if resp != nil && resp.Request != nil && !isIdempotent(resp.Request.Method) {
+		return resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusGatewayTimeout
+	}
+
+	return false
+}
+
+// alreadyRemoved reports whether a request to remove something failed only
+// because it no longer exists. A DELETE that is retried after its first
+// attempt succeeded gets a 404 on the retry.
+func alreadyRemoved(resp *http.Response) bool {
+	return resp != nil && resp.StatusCode == http.StatusNotFound
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
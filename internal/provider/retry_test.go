@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testRetryClient(maxRetries int) *http.Client {
+	return &http.Client{
+		Transport: &retryTransport{
+			base:       http.DefaultTransport,
+			maxRetries: maxRetries,
+			maxWait:    10 * time.Millisecond,
+		},
+	}
+}
+
+func TestRetryTransportRetriesThrottledRequests(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	resp, err := testRetryClient(4).Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", resp.StatusCode)
+	}
+	if requests != 3 {
+		t.Fatalf("expected 3 requests, got %d", requests)
+	}
+}
+
+func TestRetryTransportGivesUp(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	resp, err := testRetryClient(2).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if requests != 3 {
+		t.Fatalf("expected 3 requests, got %d", requests)
+	}
+}
+
+func TestRetryTransportDoesNotReplayUnclearPost(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	resp, err := testRetryClient(4).Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if requests != 1 {
+		t.Fatalf("expected 1 request, got %d", requests)
+	}
+	if !requestOutcomeUnknown(resp, nil) {
+		t.Fatal("expected the outcome of the request to be unknown")
+	}
+
+	resp, err = testRetryClient(4).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if requests != 6 {
+		t.Fatalf("expected a GET to be retried, got %d requests", requests-1)
+	}
+	if requestOutcomeUnknown(resp, nil) {
+		t.Fatal("expected the outcome of a GET to be known")
+	}
+}
+
+func TestRetryTransportBackoff(t *testing.T) {
+	transport := &retryTransport{maxWait: defaultRetryMaxWait}
+
+	for _, attempt := range []int{0, 5, 34, 63, 100} {
+		wait := transport.backoff(attempt, nil)
+		if wait < 0 || wait > defaultRetryMaxWait {
+			t.Errorf("expected the wait after attempt %d to be at most %s, got %s", attempt, defaultRetryMaxWait, wait)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if wait, ok := parseRetryAfter("5"); !ok || wait != 5*time.Second {
+		t.Errorf("expected 5s, got %s", wait)
+	}
+
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if wait, ok := parseRetryAfter(date); !ok || wait <= 0 || wait > time.Minute {
+		t.Errorf("expected a wait of up to a minute, got %s", wait)
+	}
+
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Error("expected an invalid Retry-After to be ignored")
+	}
+}
@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+const (
+	defaultCreateTimeout = 10 * time.Minute
+	defaultReadTimeout   = 5 * time.Minute
+	defaultUpdateTimeout = 10 * time.Minute
+	defaultDeleteTimeout = 10 * time.Minute
+)
+
+// addTimeoutError adds an error naming step to diags and returns true if the
+// operation's timeout expired while step was running.
+func addTimeoutError(ctx context.Context, diags *diag.Diagnostics, step string) bool {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return false
+	}
+
+	diags.AddError(
+		fmt.Sprintf("Timed out during step: %s", step),
+		fmt.Sprintf("The operation timed out while it was still running the step to %s. "+
+			"The step may still complete in RHSM. "+
+			"If the RHSM API is slow to respond, increase the timeout in the resource's timeouts block.", step),
+	)
+
+	return true
+}
@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/umich-vci/gorhsm"
+)
+
+// defaultTokenURL is the Red Hat SSO endpoint that issues RHSM access tokens.
+const defaultTokenURL = "https://sso.redhat.com/auth/realms/redhat-external/protocol/openid-connect/token"
+
+// tokenExpiryDelta is how long before its reported expiry an access token is
+// treated as expired, so requests in flight do not race the real expiry.
+const tokenExpiryDelta = time.Minute
+
+// tokenSource hands out RHSM access tokens and generates a new one when the
+// cached token is missing or about to expire. It is safe for concurrent use.
+type tokenSource struct {
+	generate func(ctx context.Context) (*gorhsm.Token, error)
+
+	mu     sync.Mutex
+	token  *gorhsm.Token
+	expiry time.Time
+}
+
+// newRefreshTokenSource returns a tokenSource that exchanges an offline
+// refresh token for access tokens at tokenURL.
+func newRefreshTokenSource(client *http.Client, tokenURL string, refreshToken string) *tokenSource {
+	return &tokenSource{
+		generate: func(ctx context.Context) (*gorhsm.Token, error) {
+			form := url.Values{}
+			form.Add("grant_type", "refresh_token")
+			form.Add("client_id", "rhsm-api")
+			form.Add("refresh_token", refreshToken)
+
+			return requestAccessToken(ctx, client, tokenURL, form)
+		},
+	}
+}
+
+// newClientCredentialsTokenSource returns a tokenSource that uses the OAuth2
+// client_credentials grant to generate access tokens for a service account.
+func newClientCredentialsTokenSource(client *http.Client, tokenURL string, clientID string, clientSecret string) *tokenSource {
+	return &tokenSource{
+		generate: func(ctx context.Context) (*gorhsm.Token, error) {
+			form := url.Values{}
+			form.Add("grant_type", "client_credentials")
+			form.Add("client_id", clientID)
+			form.Add("client_secret", clientSecret)
+
+			return requestAccessToken(ctx, client, tokenURL, form)
+		},
+	}
+}
+
+// newMissingCredentialsTokenSource returns a tokenSource that fails with err.
+// It lets the provider be configured without credentials so that commands
+// which never call the API, such as validate, do not need them.
+func newMissingCredentialsTokenSource(err error) *tokenSource {
+	return &tokenSource{
+		generate: func(ctx context.Context) (*gorhsm.Token, error) {
+			return nil, err
+		},
+	}
+}
+
+// Token returns a valid access token, generating a new one if needed.
+func (s *tokenSource) Token(ctx context.Context) (*gorhsm.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && time.Now().Before(s.expiry) {
+		return s.token, nil
+	}
+
+	token, err := s.generate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.token = token
+	s.expiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - tokenExpiryDelta)
+
+	return token, nil
+}
+
+// invalidate discards the cached token if it is still stale, so the next call
+// to Token generates a new one. Tokens already replaced by another caller are
+// left alone.
+func (s *tokenSource) invalidate(stale *gorhsm.Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == stale {
+		s.token = nil
+	}
+}
+
+// authTransport is an http.RoundTripper that adds a bearer token from a
+// tokenSource to each request. If the API rejects the token with a 401, the
+// request is retried once with a newly generated token.
+type authTransport struct {
+	tokens *tokenSource
+	base   http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.tokens.Token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errAccessToken, err)
+	}
+
+	resp, err := t.base.RoundTrip(authorizeRequest(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// the body has already been consumed and cannot be sent again
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	t.tokens.invalidate(token)
+	token, err = t.tokens.Token(req.Context())
+	if err != nil {
+		return resp, nil
+	}
+
+	retry := authorizeRequest(req, token)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+
+	resp.Body.Close()
+	return t.base.RoundTrip(retry)
+}
+
+// authorizeRequest returns a copy of req with its Authorization header set
+// from token.
+func authorizeRequest(req *http.Request, token *gorhsm.Token) *http.Request {
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", token.TokenType+" "+token.AccessToken)
+	return r
+}
+
+// tokenErrorResponse is the OAuth2 error payload returned by the SSO server.
+type tokenErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// requestAccessToken posts form to the token endpoint at tokenURL and decodes
+// the access token in the response.
+func requestAccessToken(ctx context.Context, client *http.Client, tokenURL string, form url.Values) (*gorhsm.Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(resp.Body)
+		tokenErr := new(tokenErrorResponse)
+		if json.Unmarshal(body, tokenErr) != nil || tokenErr.Error == "" {
+			return nil, fmt.Errorf("%s %s: %s: %s", req.Method, tokenURL, resp.Status, body)
+		}
+		return nil, fmt.Errorf("%s %s: %s: %s: %s", req.Method, tokenURL, resp.Status, tokenErr.Error, tokenErr.ErrorDescription)
+	}
+
+	token := new(gorhsm.Token)
+	if err := json.NewDecoder(resp.Body).Decode(token); err != nil {
+		return nil, fmt.Errorf("error decoding token response body: %w", err)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/umich-vci/gorhsm"
+)
+
+func testTokenSource(expiresIn int) (*tokenSource, *int32) {
+	var generated int32
+	return &tokenSource{
+		generate: func(ctx context.Context) (*gorhsm.Token, error) {
+			n := atomic.AddInt32(&generated, 1)
+			return &gorhsm.Token{
+				AccessToken: fmt.Sprintf("token-%d", n),
+				ExpiresIn:   expiresIn,
+				TokenType:   "Bearer",
+			}, nil
+		},
+	}, &generated
+}
+
+func TestTokenSourceCachesToken(t *testing.T) {
+	tokens, generated := testTokenSource(900)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := tokens.Token(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if *generated != 1 {
+		t.Fatalf("expected 1 token to be generated, got %d", *generated)
+	}
+}
+
+func TestTokenSourceRefreshesExpiringToken(t *testing.T) {
+	// a token that expires within tokenExpiryDelta is already stale
+	tokens, generated := testTokenSource(30)
+
+	for i := 0; i < 2; i++ {
+		if _, err := tokens.Token(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if *generated != 2 {
+		t.Fatalf("expected 2 tokens to be generated, got %d", *generated)
+	}
+}
+
+func TestAuthTransportRetriesUnauthorized(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("unexpected request body %q", body)
+		}
+
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tokens, generated := testTokenSource(900)
+	client := &http.Client{
+		Transport: &authTransport{
+			tokens: tokens,
+			base:   http.DefaultTransport,
+		},
+	}
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+	if *generated != 2 {
+		t.Fatalf("expected 2 tokens to be generated, got %d", *generated)
+	}
+}
+
+func TestRefreshTokenSourceUsesTokenURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+			return
+		}
+		if r.PostForm.Get("grant_type") != "refresh_token" || r.PostForm.Get("refresh_token") != "offline" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant","error_description":"Invalid refresh token"}`)
+			return
+		}
+		fmt.Fprint(w, `{"access_token":"access","expires_in":900,"token_type":"Bearer"}`)
+	}))
+	defer server.Close()
+
+	token, err := newRefreshTokenSource(server.Client(), server.URL, "offline").Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "access" {
+		t.Fatalf("expected access token %q, got %q", "access", token.AccessToken)
+	}
+
+	_, err = newRefreshTokenSource(server.Client(), server.URL, "wrong").Token(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Invalid refresh token") {
+		t.Fatalf("expected invalid_grant error, got %v", err)
+	}
+}
+
+func TestClientCredentialsTokenSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+			return
+		}
+		if r.PostForm.Get("grant_type") != "client_credentials" ||
+			r.PostForm.Get("client_id") != "service-account" ||
+			r.PostForm.Get("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"unauthorized_client","error_description":"Invalid client credentials"}`)
+			return
+		}
+		fmt.Fprint(w, `{"access_token":"access","expires_in":900,"token_type":"Bearer"}`)
+	}))
+	defer server.Close()
+
+	token, err := newClientCredentialsTokenSource(server.Client(), server.URL, "service-account", "secret").Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "access" {
+		t.Fatalf("expected access token %q, got %q", "access", token.AccessToken)
+	}
+}
+
+func TestAuthTransportMissingCredentials(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &authTransport{
+			tokens: newMissingCredentialsTokenSource(fmt.Errorf("no credentials")),
+			base:   http.DefaultTransport,
+		},
+	}
+
+	_, err := client.Get(server.URL)
+	if err == nil || !strings.Contains(err.Error(), "no credentials") {
+		t.Fatalf("expected a missing credentials error, got %v", err)
+	}
+	if requests != 0 {
+		t.Fatalf("expected no requests to be sent, got %d", requests)
+	}
+}
@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// transportConfig holds the network settings shared by the SSO token exchange
+// and the RHSM API client.
+type transportConfig struct {
+	// ProxyURL is the proxy to send requests through. When empty the
+	// standard HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables
+	// are used.
+	ProxyURL string
+
+	// CACertPEM is a PEM bundle of certificate authorities to trust in
+	// addition to the system certificate pool.
+	CACertPEM []byte
+
+	// ClientCertPEM and ClientKeyPEM are a PEM certificate and private key
+	// presented to servers that request client authentication.
+	ClientCertPEM []byte
+	ClientKeyPEM  []byte
+
+	InsecureSkipVerify bool
+}
+
+// newTransport returns an http.Transport configured from cfg.
+func newTransport(cfg transportConfig) (*http.Transport, error) {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected default transport type %T", http.DefaultTransport)
+	}
+	transport = transport.Clone()
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		if proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q: a scheme and host are required", cfg.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec // explicitly requested by the user
+	}
+
+	if len(cfg.CACertPEM) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(cfg.CACertPEM) {
+			return nil, fmt.Errorf("no valid PEM certificates were found in the CA bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(cfg.ClientCertPEM) > 0 || len(cfg.ClientKeyPEM) > 0 {
+		cert, err := tls.X509KeyPair(cfg.ClientCertPEM, cfg.ClientKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// logTransport is an http.RoundTripper that logs each request sent to the
+// RHSM API with the fields of the Terraform request that made it.
+type logTransport struct {
+	base http.RoundTripper
+}
+
+func (t *logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := tflog.SetField(req.Context(), "http_method", req.Method)
+	ctx = tflog.SetField(ctx, "http_url", req.URL.Redacted())
+
+	tflog.Debug(ctx, "Sending RHSM API request")
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	ctx = tflog.SetField(ctx, "duration", time.Since(start).String())
+
+	if err != nil {
+		tflog.Debug(ctx, "RHSM API request failed", map[string]interface{}{"error": err.Error()})
+		return resp, err
+	}
+
+	tflog.Debug(ctx, "Received RHSM API response", map[string]interface{}{"http_status": resp.StatusCode})
+
+	return resp, nil
+}
@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// pollInitialInterval is the time between the first polls of an operation
+// that RHSM finishes in the background. It doubles after each poll up to
+// pollMaxInterval. These are variables so that tests can shorten them.
+var (
+	pollInitialInterval = 5 * time.Second
+	pollMaxInterval     = time.Minute
+)
+
+// waitFor calls check until it reports that step is done or returns an
+// error, backing off between calls until ctx expires.
+func waitFor(ctx context.Context, step string, check func() (bool, diag.Diagnostics)) diag.Diagnostics {
+	interval := pollInitialInterval
+	for {
+		done, d := check()
+		if done || d.HasError() {
+			return d
+		}
+
+		tflog.Debug(ctx, "Waiting to "+step, map[string]interface{}{"interval": interval.String()})
+
+		select {
+		case <-ctx.Done():
+			if !addTimeoutError(ctx, &d, step) {
+				d.AddError("Failed to "+step, ctx.Err().Error())
+			}
+			return d
+		case <-time.After(interval):
+		}
+
+		interval = min(interval*2, pollMaxInterval)
+	}
+}